@@ -0,0 +1,88 @@
+package sqlslog
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ArgsMode is how the args of the queries are logged.
+type ArgsMode int
+
+const (
+	ArgsLogged   ArgsMode = iota // The args are logged as they are.
+	ArgsRedacted                 // The values of the args are replaced with ArgsRedactedValue.
+	ArgsOmitted                  // The args are not logged.
+)
+
+// ArgsRedactedValue is the value which replaces the values of the args with ArgsRedacted.
+const ArgsRedactedValue = "[REDACTED]"
+
+var argsModeNames = map[ArgsMode]string{
+	ArgsLogged:   "log",
+	ArgsRedacted: "redact",
+	ArgsOmitted:  "omit",
+}
+
+// String returns the name of the mode used by [ParseArgsMode].
+func (m ArgsMode) String() string {
+	if s, ok := argsModeNames[m]; ok {
+		return s
+	}
+	return fmt.Sprintf("ArgsMode(%d)", int(m))
+}
+
+// MarshalText implements encoding.TextMarshaler by String.
+func (m ArgsMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler by ParseArgsMode.
+func (m *ArgsMode) UnmarshalText(data []byte) error {
+	r, err := ParseArgsMode(string(data))
+	if err != nil {
+		return err
+	}
+	*m = r
+	return nil
+}
+
+// ErrUnknownArgsMode is returned by [ParseArgsMode] for unknown names.
+var ErrUnknownArgsMode = errors.New("unknown args mode")
+
+// ParseArgsMode parses "log", "redact" or "omit" regardless of the case.
+func ParseArgsMode(s string) (ArgsMode, error) {
+	for m, name := range argsModeNames {
+		if strings.EqualFold(name, s) {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownArgsMode, s)
+}
+
+// Args is an option to specify how the args of the queries are logged.
+// The default is ArgsLogged.
+func Args(mode ArgsMode) Option {
+	return func(o *options) { o.stepLoggerOptions.argsMode = mode }
+}
+
+// redactArgs returns a copy of the args whose values are replaced with ArgsRedactedValue.
+func redactArgs(args interface{}) interface{} {
+	switch v := args.(type) {
+	case []driver.Value:
+		r := make([]driver.Value, len(v))
+		for i := range r {
+			r[i] = ArgsRedactedValue
+		}
+		return r
+	case []driver.NamedValue:
+		r := make([]driver.NamedValue, len(v))
+		for i, nv := range v {
+			r[i] = driver.NamedValue{Name: nv.Name, Ordinal: nv.Ordinal, Value: ArgsRedactedValue}
+		}
+		return r
+	default:
+		return args
+	}
+}
@@ -0,0 +1,80 @@
+package sqlslog
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestArgs(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name     string
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "default",
+			expected: "level=INFO msg=Conn.ExecContext query=\"DELETE FROM users WHERE id = ?\" args=\"[{Name: Ordinal:1 Value:1}]\"\n",
+		},
+		{
+			name:     "redacted",
+			opts:     []Option{Args(ArgsRedacted)},
+			expected: "level=INFO msg=Conn.ExecContext query=\"DELETE FROM users WHERE id = ?\" args=\"[{Name: Ordinal:1 Value:[REDACTED]}]\"\n",
+		},
+		{
+			name:     "omitted",
+			opts:     []Option{Args(ArgsOmitted)},
+			expected: "level=INFO msg=Conn.ExecContext query=\"DELETE FROM users WHERE id = ?\"\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			buf := bytes.NewBuffer(nil)
+			o := newOptions("sqlite3", tc.opts...)
+			handler := NewTextHandler(buf, &slog.HandlerOptions{ReplaceAttr: removeTimeAndDurationForTest})
+			logger := newStepLogger(slog.New(handler), o.stepLoggerOptions)
+			conn := wrapConn(&mockResultConn{}, logger, o.DriverOptions.ConnOptions)
+			args := []driver.NamedValue{{Ordinal: 1, Value: 1}}
+			if _, err := conn.(driver.ExecerContext).ExecContext(context.Background(), "DELETE FROM users WHERE id = ?", args); err != nil {
+				t.Fatal(err)
+			}
+			if actual := buf.String(); actual != tc.expected {
+				t.Errorf("Expected %q but got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	t.Parallel()
+	values := []driver.Value{"secret", 1}
+	if actual, ok := redactArgs(values).([]driver.Value); !ok || len(actual) != 2 || actual[0] != ArgsRedactedValue {
+		t.Errorf("Unexpected result: %v", actual)
+	}
+	if values[0] != "secret" {
+		t.Errorf("Expected the original args not to be modified: %v", values)
+	}
+	if actual := redactArgs(nil); actual != nil {
+		t.Errorf("Expected nil but got %v", actual)
+	}
+}
+
+func TestParseArgsMode(t *testing.T) {
+	t.Parallel()
+	for s, expected := range map[string]ArgsMode{"log": ArgsLogged, "REDACT": ArgsRedacted, "omit": ArgsOmitted} {
+		if actual, err := ParseArgsMode(s); err != nil || actual != expected {
+			t.Errorf("%s: expected %v but got %v %v", s, expected, actual, err)
+		}
+		if expected.String() != strings.ToLower(s) {
+			t.Errorf("Expected %s but got %s", strings.ToLower(s), expected.String())
+		}
+	}
+	if _, err := ParseArgsMode("mask"); !errors.Is(err, ErrUnknownArgsMode) {
+		t.Errorf("Expected ErrUnknownArgsMode but got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package sqlslog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// AttrGroup puts all the attributes of the logs by sqlslog into the group with the name
+// such as sql.query and sql.conn_id, so they don't collide with the keys of the application.
+// The logs by the logger returned by [Open] or [Factory.Logger] are not affected.
+// If it's empty, the attributes are not grouped. The default is empty.
+func AttrGroup(name string) Option {
+	return func(o *options) { o.SlogOptions.attrGroup = name }
+}
+
+// RenameAttr renames the key of the attributes of the logs by sqlslog such as
+// query, args, dsn, driver, error, eof, skip and success.
+// The keys which can be set by the options such as [DurationKey] and [ConnIDKey] can be renamed too.
+// The attributes in groups are not renamed.
+func RenameAttr(key, newKey string) Option {
+	return func(o *options) {
+		if o.SlogOptions.attrKeys == nil {
+			o.SlogOptions.attrKeys = map[string]string{}
+		}
+		o.SlogOptions.attrKeys[key] = newKey
+	}
+}
+
+// stepSlogLogger returns the logger for the steps with the group and the renamed keys.
+func (f *Factory) stepSlogLogger() *slog.Logger {
+	o := f.options.SlogOptions
+	lg := f.Logger()
+	if len(o.attrKeys) > 0 {
+		lg = slog.New(&renameHandler{handler: f.Handler(), keys: o.attrKeys})
+	}
+	if o.attrGroup != "" {
+		lg = lg.WithGroup(o.attrGroup)
+	}
+	return lg
+}
+
+// renameHandler is the handler which renames the keys of the attributes.
+type renameHandler struct {
+	handler slog.Handler
+	keys    map[string]string
+}
+
+var _ slog.Handler = (*renameHandler)(nil)
+
+func (h *renameHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *renameHandler) Handle(ctx context.Context, r slog.Record) error {
+	renamed := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		renamed.AddAttrs(h.rename(a))
+		return true
+	})
+	return h.handler.Handle(ctx, renamed)
+}
+
+func (h *renameHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	renamed := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		renamed[i] = h.rename(a)
+	}
+	return &renameHandler{handler: h.handler.WithAttrs(renamed), keys: h.keys}
+}
+
+func (h *renameHandler) WithGroup(name string) slog.Handler {
+	return &renameHandler{handler: h.handler.WithGroup(name), keys: h.keys}
+}
+
+func (h *renameHandler) rename(a slog.Attr) slog.Attr {
+	if key, ok := h.keys[a.Key]; ok {
+		a.Key = key
+	}
+	return a
+}
@@ -0,0 +1,65 @@
+package sqlslog
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"testing"
+)
+
+func TestAttrGroupAndRenameAttr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "default",
+			expected: `level=INFO msg=Conn.ExecContext query="DELETE FROM users" args=[]` + "\n",
+		},
+		{
+			name:     "group",
+			opts:     []Option{AttrGroup("sql")},
+			expected: `level=INFO msg=Conn.ExecContext sql.query="DELETE FROM users" sql.args=[]` + "\n",
+		},
+		{
+			name:     "rename",
+			opts:     []Option{RenameAttr("query", "statement"), RenameAttr("duration", "elapsed")},
+			expected: `level=INFO msg=Conn.ExecContext statement="DELETE FROM users" args=[]` + "\n",
+		},
+		{
+			name:     "group and rename",
+			opts:     []Option{AttrGroup("sql"), RenameAttr("args", "params")},
+			expected: `level=INFO msg=Conn.ExecContext sql.query="DELETE FROM users" sql.params=[]` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			buf := bytes.NewBuffer(nil)
+			f := New("sqlite3", "", append([]Option{
+				LogWriter(buf),
+				// The durations are removed by the keys in the group and the renamed keys.
+				LogReplaceAttr(func(_ []string, a slog.Attr) slog.Attr {
+					if a.Key == slog.TimeKey || a.Key == "duration" || a.Key == "elapsed" {
+						return slog.Attr{}
+					}
+					return a
+				}),
+			}, tt.opts...)...)
+			logger := newStepLogger(f.stepSlogLogger(), f.options.stepLoggerOptions)
+			conn := wrapConn(&mockResultConn{}, logger, f.options.DriverOptions.ConnOptions)
+			if _, err := conn.(driver.ExecerContext).ExecContext(context.Background(), "DELETE FROM users", nil); err != nil {
+				t.Fatal(err)
+			}
+			f.Logger().Info("app", slog.String("query", "q"))
+			expected := tt.expected + `level=INFO msg=app query=q` + "\n"
+			if actual := buf.String(); actual != expected {
+				t.Errorf("Expected %q but got %q", expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package sqlslog
+
+// BadConnLevel sets the level for Error events of the steps which fail with driver.ErrBadConn.
+// database/sql retries the operation on another connection and discards the connection
+// when it gets driver.ErrBadConn from any step including Conn.ResetSession.
+// It also discards the connection when Conn.IsValid returns false,
+// and sqlslog logs it as an Error event of Conn.IsValid in the same way.
+// The events have the attribute with BadConnKey and true.
+// The default is BadConnLevelDefault.
+func BadConnLevel(lv Level) Option {
+	return func(o *options) { o.stepLoggerOptions.badConnLevel = lv }
+}
+
+const (
+	BadConnKey          = "bad_conn" // Key for the attribute of Error events with driver.ErrBadConn.
+	BadConnLevelDefault = LevelWarn  // Default level for [BadConnLevel].
+)
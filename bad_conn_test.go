@@ -0,0 +1,83 @@
+package sqlslog
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+type mockValidatorConn struct {
+	mockErrorConn
+	valid bool
+}
+
+func (m *mockValidatorConn) IsValid() bool { return m.valid }
+
+func TestConnIsValid(t *testing.T) {
+	t.Parallel()
+
+	run := func(valid bool, opts ...Option) (bool, string) {
+		buf := bytes.NewBuffer(nil)
+		o := newOptions("sqlite3", opts...)
+		handler := NewTextHandler(buf, &slog.HandlerOptions{Level: LevelVerbose, ReplaceAttr: removeTimeAndDurationForTest})
+		logger := newStepLogger(slog.New(handler), o.stepLoggerOptions)
+		conn := wrapConn(&mockValidatorConn{valid: valid}, logger, o.DriverOptions.ConnOptions)
+		return conn.(driver.Validator).IsValid(), buf.String()
+	}
+
+	if valid, out := run(true); !valid || out != "" {
+		t.Errorf("Unexpected result: %v %q", valid, out)
+	}
+	if valid, out := run(false); valid || out != "level=WARN msg=Conn.IsValid error=\"connection is invalid: driver: bad connection\" bad_conn=true\n" {
+		t.Errorf("Unexpected result: %v %q", valid, out)
+	}
+	if valid, out := run(true, ConnIsValid(func(o *StepOptions) { o.SetLevel(LevelTrace) })); !valid ||
+		out != "level=VERBOSE msg=Conn.IsValid\nlevel=TRACE msg=Conn.IsValid\n" {
+		t.Errorf("Unexpected result: %v %q", valid, out)
+	}
+}
+
+func TestBadConnLevel(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name     string
+		err      error
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "bad conn",
+			err:      driver.ErrBadConn,
+			expected: "level=WARN msg=Conn.ExecContext query=\"DELETE FROM users\" args=[] error=\"driver: bad connection\" bad_conn=true\n",
+		},
+		{
+			name:     "bad conn with level",
+			err:      driver.ErrBadConn,
+			opts:     []Option{BadConnLevel(LevelInfo)},
+			expected: "level=INFO msg=Conn.ExecContext query=\"DELETE FROM users\" args=[] error=\"driver: bad connection\" bad_conn=true\n",
+		},
+		{
+			name:     "other error",
+			err:      errors.New("unexpected error"),
+			expected: "level=ERROR msg=Conn.ExecContext query=\"DELETE FROM users\" args=[] error=\"unexpected error\"\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			buf := bytes.NewBuffer(nil)
+			o := newOptions("sqlite3", tc.opts...)
+			handler := NewTextHandler(buf, &slog.HandlerOptions{Level: LevelInfo, ReplaceAttr: removeTimeAndDurationForTest})
+			logger := newStepLogger(slog.New(handler), o.stepLoggerOptions)
+			conn := wrapConn(newMockErrConn(tc.err), logger, o.DriverOptions.ConnOptions)
+			if _, err := conn.(driver.ExecerContext).ExecContext(context.Background(), "DELETE FROM users", nil); !errors.Is(err, tc.err) {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if actual := buf.String(); actual != tc.expected {
+				t.Errorf("Expected %q but got %q", tc.expected, actual)
+			}
+		})
+	}
+}
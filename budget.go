@@ -0,0 +1,148 @@
+package sqlslog
+
+import (
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// BudgetReport is the summary of the database work done under a context given by [TrackQueries].
+type BudgetReport struct {
+	Queries      int           // Number of the queries.
+	Duration     time.Duration // Total duration of the steps.
+	RowsRead     int64         // Number of the rows read by Rows.Next.
+	RowsAffected int64         // Number of the rows affected by Exec.
+	Duplicates   int           // Number of the redundant repeats of the queries with the same args.
+	Wasted       time.Duration // Total duration of the redundant repeats.
+
+	// seconds is true if the queries are logged with SemConv. The durations are logged in seconds as float.
+	seconds bool
+}
+
+// Budget returns the report of the database work done under the context.
+// The context must be given by [TrackQueries]. Otherwise, it returns a zero report.
+func Budget(ctx context.Context) BudgetReport {
+	tracker := queryTrackerFrom(ctx)
+	if tracker == nil {
+		return BudgetReport{}
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	return tracker.budget
+}
+
+// BudgetLimits is the limits for [BudgetReport].
+// Zero values mean no limit.
+type BudgetLimits struct {
+	Queries      int
+	Duration     time.Duration
+	RowsRead     int64
+	RowsAffected int64
+}
+
+const (
+	budgetQueriesKey      = "queries"
+	budgetDurationKey     = "db_duration"
+	budgetRowsReadKey     = "rows_read"
+	budgetRowsAffectedKey = "rows_affected"
+	budgetDuplicatesKey   = "duplicates"
+	budgetWastedKey       = "wasted_duration"
+	budgetExceededKey     = "exceeded"
+)
+
+// Exceeded returns the names of the limits which the report exceeds.
+// The names are the same as the keys in the log: queries, db_duration, rows_read and rows_affected.
+func (r BudgetReport) Exceeded(limits BudgetLimits) []string {
+	var names []string
+	if limits.Queries > 0 && r.Queries > limits.Queries {
+		names = append(names, budgetQueriesKey)
+	}
+	if limits.Duration > 0 && r.Duration > limits.Duration {
+		names = append(names, budgetDurationKey)
+	}
+	if limits.RowsRead > 0 && r.RowsRead > limits.RowsRead {
+		names = append(names, budgetRowsReadKey)
+	}
+	if limits.RowsAffected > 0 && r.RowsAffected > limits.RowsAffected {
+		names = append(names, budgetRowsAffectedKey)
+	}
+	return names
+}
+
+// Attrs returns the attributes of the report.
+// The durations are in seconds as float if the queries are logged with [SemConv].
+func (r BudgetReport) Attrs() []slog.Attr {
+	o := &stepLoggerOptions{semconv: r.seconds}
+	return []slog.Attr{
+		slog.Int(budgetQueriesKey, r.Queries),
+		o.durationAttrWithKey(budgetDurationKey, r.Duration),
+		slog.Int64(budgetRowsReadKey, r.RowsRead),
+		slog.Int64(budgetRowsAffectedKey, r.RowsAffected),
+		slog.Int(budgetDuplicatesKey, r.Duplicates),
+		o.durationAttrWithKey(budgetWastedKey, r.Wasted),
+	}
+}
+
+// LogBudget logs the budget report of the context.
+// It logs at [LevelInfo] or at [LevelWarn] with the names of the exceeded limits if limits are exceeded.
+func LogBudget(ctx context.Context, logger *slog.Logger, msg string, limits BudgetLimits, attrs ...slog.Attr) {
+	report := Budget(ctx)
+	attrs = append(attrs, report.Attrs()...)
+	level := LevelInfo
+	if exceeded := report.Exceeded(limits); len(exceeded) > 0 {
+		level = LevelWarn
+		attrs = append(attrs, slog.Any(budgetExceededKey, exceeded))
+	}
+	logger.LogAttrs(ctx, slog.Level(level), msg, attrs...)
+}
+
+// BudgetMsgDefault is the default message for the budget summary logged by [BudgetMiddleware].
+const BudgetMsgDefault = "Request budget"
+
+// BudgetMiddleware returns an HTTP middleware which tracks the queries for each request by [TrackQueries]
+// and logs the budget summary with the method and the path of the request at the end of the request.
+// See [LogBudget] for the log.
+func BudgetMiddleware(logger *slog.Logger, limits BudgetLimits) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := TrackQueries(r.Context())
+			defer LogBudget(ctx, logger, BudgetMsgDefault, limits,
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+			)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func (t *queryTracker) addDuration(d time.Duration, seconds bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.budget.Duration += d
+	t.budget.seconds = t.budget.seconds || seconds
+}
+
+func (t *queryTracker) addRowsRead(n int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.budget.RowsRead += n
+}
+
+func (t *queryTracker) addRowsAffected(n int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.budget.RowsAffected += n
+}
+
+// trackResult records the rows affected by the result under the context given by [TrackQueries].
+func trackResult(ctx context.Context, result driver.Result) {
+	tracker := queryTrackerFrom(ctx)
+	if tracker == nil || result == nil {
+		return
+	}
+	if n, err := result.RowsAffected(); err == nil {
+		tracker.addRowsAffected(n)
+	}
+}
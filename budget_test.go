@@ -0,0 +1,119 @@
+package sqlslog
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockResultConn is a driver.Conn which returns results with rows.
+type mockResultConn struct {
+	mockErrorConn
+	rowsAffected int64
+	rows         int
+}
+
+func (m *mockResultConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(m.rowsAffected), nil
+}
+
+func (m *mockResultConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return &mockTx{}, nil
+}
+
+func (m *mockResultConn) PrepareContext(context.Context, string) (driver.Stmt, error) {
+	return &mockStmtForWrapStmt{}, nil
+}
+
+func (m *mockResultConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &mockResultRows{rest: m.rows}, nil
+}
+
+type mockResultRows struct {
+	rest int
+}
+
+func (m *mockResultRows) Close() error      { return nil }
+func (m *mockResultRows) Columns() []string { return []string{"id"} }
+func (m *mockResultRows) Next(dest []driver.Value) error {
+	if m.rest <= 0 {
+		return io.EOF
+	}
+	m.rest--
+	if len(dest) > 0 {
+		dest[0] = int64(m.rest)
+	}
+	return nil
+}
+
+func TestBudget(t *testing.T) {
+	t.Parallel()
+	if r := Budget(context.Background()); r != (BudgetReport{}) {
+		t.Fatalf("Expected zero report, got %+v", r)
+	}
+
+	opts := newOptions("sqlite3")
+	logger := newStepLogger(slog.New(NewTextHandler(io.Discard, nil)), opts.stepLoggerOptions)
+	conn := wrapConn(&mockResultConn{rowsAffected: 3, rows: 2}, logger, opts.DriverOptions.ConnOptions)
+
+	ctx := TrackQueries(context.Background())
+	if _, err := conn.(driver.ExecerContext).ExecContext(ctx, "DELETE FROM users", nil); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := conn.(driver.QueryerContext).QueryContext(ctx, "SELECT id FROM users", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest := make([]driver.Value, 1)
+	for rows.Next(dest) == nil { // nolint:revive
+	}
+
+	r := Budget(ctx)
+	if r.Queries != 2 || r.RowsAffected != 3 || r.RowsRead != 2 || r.Duration <= 0 {
+		t.Fatalf("Unexpected report: %+v", r)
+	}
+	if exceeded := r.Exceeded(BudgetLimits{}); len(exceeded) != 0 {
+		t.Fatalf("Unexpected exceeded: %v", exceeded)
+	}
+	exceeded := r.Exceeded(BudgetLimits{Queries: 1, Duration: time.Nanosecond, RowsRead: 1, RowsAffected: 1})
+	if strings.Join(exceeded, ",") != "queries,db_duration,rows_read,rows_affected" {
+		t.Fatalf("Unexpected exceeded: %v", exceeded)
+	}
+}
+
+func TestBudgetMiddleware(t *testing.T) {
+	t.Parallel()
+	opts := newOptions("sqlite3")
+	logger := newStepLogger(slog.New(NewTextHandler(io.Discard, nil)), opts.stepLoggerOptions)
+	conn := wrapConn(&mockResultConn{rowsAffected: 1}, logger, opts.DriverOptions.ConnOptions)
+
+	handler := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		for range 2 {
+			if _, err := conn.(driver.ExecerContext).ExecContext(r.Context(), "DELETE FROM users", nil); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	run := func(limits BudgetLimits) string {
+		buf := bytes.NewBuffer(nil)
+		mw := BudgetMiddleware(slog.New(NewTextHandler(buf, &slog.HandlerOptions{ReplaceAttr: removeTimeAndDurationForTest})), limits)
+		mw(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/todos", nil))
+		return buf.String()
+	}
+
+	if out := run(BudgetLimits{}); !strings.HasPrefix(out, `level=INFO msg="Request budget" method=GET path=/todos queries=2 db_duration=`) ||
+		!strings.Contains(out, " rows_read=0 rows_affected=2 duplicates=1 wasted_duration=") {
+		t.Fatalf("Unexpected log: %q", out)
+	}
+	if out := run(BudgetLimits{Queries: 1}); !strings.HasPrefix(out, "level=WARN") || !strings.HasSuffix(out, " exceeded=[queries]\n") {
+		t.Fatalf("Unexpected log: %q", out)
+	}
+}
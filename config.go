@@ -0,0 +1,334 @@
+package sqlslog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Config is the declarative configuration of sqlslog which can be kept in the application config.
+// It's decoded from JSON by [LoadConfig] and converted into the options by [Config.Options].
+// The zero values and the nil pointers mean the defaults.
+// The booleans are pointers, so false can disable the features enabled by the other options.
+type Config struct {
+	Level     *Level `json:"level,omitempty"`      // See LogLevel.
+	Format    string `json:"format,omitempty"`     // json or text. See HandlerFunc.
+	AddSource *bool  `json:"add_source,omitempty"` // See AddSource.
+
+	Duration    *DurationType `json:"duration,omitempty"`     // ns, us, ms, duration, string or s. See Duration.
+	DurationKey string        `json:"duration_key,omitempty"` // See DurationKey.
+
+	AttrGroup   string            `json:"attr_group,omitempty"`   // See AttrGroup.
+	RenameAttrs map[string]string `json:"rename_attrs,omitempty"` // The new keys by the keys. See RenameAttr.
+
+	ConnIDKey string `json:"conn_id_key,omitempty"` // See ConnIDKey.
+	TxIDKey   string `json:"tx_id_key,omitempty"`   // See TxIDKey.
+	StmtIDKey string `json:"stmt_id_key,omitempty"` // See StmtIDKey.
+
+	Name     string            `json:"name,omitempty"`      // See Name.
+	Attrs    map[string]string `json:"attrs,omitempty"`     // See Attrs.
+	DSNAttrs *bool             `json:"dsn_attrs,omitempty"` // See DSNAttrs.
+	SemConv  *bool             `json:"semconv,omitempty"`   // See SemConv.
+
+	Dialect string    `json:"dialect,omitempty"` // The driver name of the registered dialect. See UseDialect.
+	Args    *ArgsMode `json:"args,omitempty"`    // log, redact or omit. See Args.
+
+	QueryFingerprint *bool `json:"query_fingerprint,omitempty"` // See QueryFingerprint.
+	QueryName        *bool `json:"query_name,omitempty"`        // See QueryName.
+	StripQueryName   *bool `json:"strip_query_name,omitempty"`  // See StripQueryName.
+	QueryOperation   *bool `json:"query_operation,omitempty"`   // See QueryOperation.
+	QueryTables      *bool `json:"query_tables,omitempty"`      // See QueryTables.
+	Tracing          *bool `json:"tracing,omitempty"`           // See Tracing.
+	DetectLeaks      *bool `json:"detect_leaks,omitempty"`      // See DetectLeaks.
+	DebugRegistry    *bool `json:"debug_registry,omitempty"`    // See DebugRegistry.
+	ErrorEnrichment  *bool `json:"error_enrichment,omitempty"`  // See ErrorEnrichment.
+
+	NPlusOneThreshold       *int            `json:"n_plus_one_threshold,omitempty"`      // See NPlusOneThreshold.
+	DuplicateQueryThreshold *int            `json:"duplicate_query_threshold,omitempty"` // See DuplicateQueryThreshold.
+	ExplainSlowQueries      *ConfigDuration `json:"explain_slow_queries,omitempty"`      // See ExplainSlowQueries. "0s" disables it.
+	LeakAge                 ConfigDuration  `json:"leak_age,omitempty"`                  // See LeakAge.
+	RecentQueries           int             `json:"recent_queries,omitempty"`            // See RecentQueries.
+	DBStatsInterval         ConfigDuration  `json:"db_stats_interval,omitempty"`         // See DBStatsInterval.
+	BadConnLevel            *Level          `json:"bad_conn_level,omitempty"`            // See BadConnLevel.
+	SampleRate              *float64        `json:"sample_rate,omitempty"`               // See Sampling.
+
+	// Steps is the configurations of the steps by the step names such as "Conn.Begin"
+	// or the patterns of Step.Match such as "Rows.*" and "lifecycle".
+	// The configurations for the patterns are applied before the ones for the step names.
+	Steps map[Step]*StepConfig `json:"steps,omitempty"`
+}
+
+// StepConfig is the configuration of a step.
+type StepConfig struct {
+	Level    *Level       `json:"level,omitempty"` // See StepOptions.SetLevel. It's applied before the events.
+	Start    *EventConfig `json:"start,omitempty"`
+	Error    *EventConfig `json:"error,omitempty"`
+	Complete *EventConfig `json:"complete,omitempty"`
+	Canceled *EventConfig `json:"canceled,omitempty"`
+	Timeout  *EventConfig `json:"timeout,omitempty"`
+}
+
+// EventConfig is the configuration of an event of a step.
+type EventConfig struct {
+	Msg   string `json:"msg,omitempty"`
+	Level *Level `json:"level,omitempty"`
+}
+
+// ConfigDuration is time.Duration which is encoded as a string such as "500ms" in JSON.
+type ConfigDuration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d ConfigDuration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler by time.ParseDuration.
+func (d *ConfigDuration) UnmarshalText(data []byte) error {
+	v, err := time.ParseDuration(string(data))
+	if err != nil {
+		return err
+	}
+	*d = ConfigDuration(v)
+	return nil
+}
+
+// ErrInvalidConfig is returned by [LoadConfig] and [Config.Validate] for the invalid configurations.
+var ErrInvalidConfig = errors.New("invalid config")
+
+// LoadConfig decodes the configuration from JSON and validates it.
+// Unknown fields are reported as errors to find typos.
+func LoadConfig(r io.Reader) (*Config, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var c Config
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Validate returns an error which wraps [ErrInvalidConfig] for each invalid value.
+func (c *Config) Validate() error {
+	var errs []error
+	switch strings.ToLower(c.Format) {
+	case "", "json", "text":
+	default:
+		errs = append(errs, fmt.Errorf("%w: format %q: %w", ErrInvalidConfig, c.Format, errUnknownFormat))
+	}
+	if c.Dialect != "" && LookupDialect(c.Dialect) == nil {
+		errs = append(errs, fmt.Errorf("%w: dialect %q is not registered", ErrInvalidConfig, c.Dialect))
+	}
+	if c.SampleRate != nil && (*c.SampleRate < 0 || *c.SampleRate > 1) {
+		errs = append(errs, fmt.Errorf("%w: sample_rate %v is out of range from 0 to 1", ErrInvalidConfig, *c.SampleRate))
+	}
+	for _, step := range c.stepNames() {
+		if !matchesAnyStep(string(step)) {
+			errs = append(errs, fmt.Errorf("%w: %w %q", ErrInvalidConfig, errUnknownStep, step))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Options returns the options for the configuration. The invalid values are ignored.
+func (c *Config) Options() []Option { // nolint:cyclop,funlen,gocognit,gocyclo
+	var r []Option
+
+	if c.Level != nil {
+		r = append(r, LogLevel(*c.Level))
+	}
+	if strings.EqualFold(c.Format, "json") {
+		r = append(r, HandlerFunc(NewJSONHandler))
+	}
+	if strings.EqualFold(c.Format, "text") {
+		r = append(r, HandlerFunc(NewTextHandler))
+	}
+	if c.AddSource != nil {
+		r = append(r, AddSource(*c.AddSource))
+	}
+
+	if c.Duration != nil {
+		r = append(r, Duration(*c.Duration))
+	}
+	if c.DurationKey != "" {
+		r = append(r, DurationKey(c.DurationKey))
+	}
+
+	if c.AttrGroup != "" {
+		r = append(r, AttrGroup(c.AttrGroup))
+	}
+	if len(c.RenameAttrs) > 0 {
+		keys := make([]string, 0, len(c.RenameAttrs))
+		for k := range c.RenameAttrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			r = append(r, RenameAttr(k, c.RenameAttrs[k]))
+		}
+	}
+
+	if c.ConnIDKey != "" {
+		r = append(r, ConnIDKey(c.ConnIDKey))
+	}
+	if c.TxIDKey != "" {
+		r = append(r, TxIDKey(c.TxIDKey))
+	}
+	if c.StmtIDKey != "" {
+		r = append(r, StmtIDKey(c.StmtIDKey))
+	}
+
+	if c.Name != "" {
+		r = append(r, Name(c.Name))
+	}
+	if len(c.Attrs) > 0 {
+		keys := make([]string, 0, len(c.Attrs))
+		for k := range c.Attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		attrs := make([]slog.Attr, len(keys))
+		for i, k := range keys {
+			attrs[i] = slog.String(k, c.Attrs[k])
+		}
+		r = append(r, Attrs(attrs...))
+	}
+	if c.DSNAttrs != nil {
+		r = append(r, DSNAttrs(*c.DSNAttrs))
+	}
+	if c.SemConv != nil {
+		r = append(r, SemConv(*c.SemConv))
+	}
+
+	if c.Dialect != "" && LookupDialect(c.Dialect) != nil {
+		r = append(r, UseDialect(LookupDialect(c.Dialect)))
+	}
+	if c.Args != nil {
+		r = append(r, Args(*c.Args))
+	}
+
+	if c.QueryFingerprint != nil {
+		r = append(r, QueryFingerprint(*c.QueryFingerprint))
+	}
+	if c.QueryName != nil {
+		r = append(r, QueryName(*c.QueryName))
+	}
+	if c.StripQueryName != nil {
+		r = append(r, StripQueryName(*c.StripQueryName))
+	}
+	if c.QueryOperation != nil {
+		r = append(r, QueryOperation(*c.QueryOperation))
+	}
+	if c.QueryTables != nil {
+		r = append(r, QueryTables(*c.QueryTables))
+	}
+	if c.Tracing != nil {
+		r = append(r, Tracing(*c.Tracing))
+	}
+	if c.DetectLeaks != nil {
+		r = append(r, DetectLeaks(*c.DetectLeaks))
+	}
+	if c.DebugRegistry != nil {
+		r = append(r, DebugRegistry(*c.DebugRegistry))
+	}
+	if c.ErrorEnrichment != nil {
+		r = append(r, ErrorEnrichment(*c.ErrorEnrichment))
+	}
+
+	if c.NPlusOneThreshold != nil {
+		r = append(r, NPlusOneThreshold(*c.NPlusOneThreshold))
+	}
+	if c.DuplicateQueryThreshold != nil {
+		r = append(r, DuplicateQueryThreshold(*c.DuplicateQueryThreshold))
+	}
+	if c.ExplainSlowQueries != nil {
+		r = append(r, ExplainSlowQueries(time.Duration(*c.ExplainSlowQueries)))
+	}
+	if c.LeakAge > 0 {
+		r = append(r, LeakAge(time.Duration(c.LeakAge)))
+	}
+	if c.RecentQueries > 0 {
+		r = append(r, RecentQueries(c.RecentQueries))
+	}
+	if c.DBStatsInterval > 0 {
+		r = append(r, DBStatsInterval(time.Duration(c.DBStatsInterval)))
+	}
+	if c.BadConnLevel != nil {
+		r = append(r, BadConnLevel(*c.BadConnLevel))
+	}
+	if c.SampleRate != nil && *c.SampleRate >= 0 && *c.SampleRate <= 1 {
+		r = append(r, Sampling(*c.SampleRate))
+	}
+
+	for _, step := range c.stepNames() {
+		if sc := c.Steps[step]; sc != nil {
+			r = append(r, Steps(string(step), func(_ Step, o *StepOptions) { sc.apply(o) }))
+		}
+	}
+	return r
+}
+
+// stepNames returns the step names and the patterns in Steps in order.
+func (c *Config) stepNames() []Step {
+	r := make([]Step, 0, len(c.Steps))
+	for step := range c.Steps {
+		r = append(r, step)
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if isStep(r[i]) != isStep(r[j]) {
+			return !isStep(r[i])
+		}
+		return r[i] < r[j]
+	})
+	return r
+}
+
+func (c *StepConfig) apply(o *StepOptions) {
+	if c.Level != nil {
+		o.SetLevel(*c.Level)
+	}
+	c.Start.apply(&o.Start)
+	c.Error.apply(&o.Error)
+	c.Complete.apply(&o.Complete)
+	c.Canceled.apply(&o.Canceled)
+	c.Timeout.apply(&o.Timeout)
+}
+
+func (c *EventConfig) apply(o *EventOptions) {
+	if c == nil {
+		return
+	}
+	if c.Msg != "" {
+		o.Msg = c.Msg
+	}
+	if c.Level != nil {
+		o.Level = *c.Level
+	}
+}
+
+// isStep returns true if the step is one of the steps.
+func isStep(step Step) bool {
+	for _, s := range steps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAnyStep returns true if the pattern matches at least one step.
+func matchesAnyStep(pattern string) bool {
+	for _, s := range steps {
+		if s.Match(pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package sqlslog
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+		c, err := LoadConfig(strings.NewReader(`{
+			"level": "TRACE",
+			"format": "json",
+			"duration": "ms",
+			"duration_key": "elapsed",
+			"conn_id_key": "cid",
+			"dialect": "postgres",
+			"name": "primary",
+			"attr_group": "sql",
+			"semconv": true,
+			"rename_attrs": {"query": "statement", "args": "params"},
+			"attrs": {"region": "us", "az": "a"},
+			"args": "redact",
+			"query_fingerprint": true,
+			"n_plus_one_threshold": 0,
+			"explain_slow_queries": "500ms",
+			"bad_conn_level": "INFO",
+			"steps": {
+				"Conn.Begin": {"start": {"msg": "Conn.Begin Start"}, "complete": {"msg": "Conn.Begin Complete", "level": "WARN"}},
+				"Rows.Next": {"level": "DEBUG-2"},
+				"Rows.*": {"level": "ERROR", "error": {"msg": "Rows Error"}}
+			}
+		}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		o := newOptions("sqlite3", c.Options()...)
+		if o.SlogOptions.Level != LevelTrace {
+			t.Errorf("Unexpected level: %v", o.SlogOptions.Level)
+		}
+		if o.durationType != DurationMilliSeconds || o.durationKey != "elapsed" {
+			t.Errorf("Unexpected duration: %v %s", o.durationType, o.durationKey)
+		}
+		if o.DriverOptions.ConnIDKey != "cid" {
+			t.Errorf("Unexpected conn_id key: %s", o.DriverOptions.ConnIDKey)
+		}
+		if o.dialect != DialectPostgres || o.argsMode != ArgsRedacted || !o.queryOptions.fingerprint {
+			t.Errorf("Unexpected options: %v %v %v", o.dialect, o.argsMode, o.queryOptions.fingerprint)
+		}
+		if !o.semconv {
+			t.Error("Expected semconv to be enabled")
+		}
+		if o.SlogOptions.attrGroup != "sql" || o.SlogOptions.attrKeys["query"] != "statement" || o.SlogOptions.attrKeys["args"] != "params" {
+			t.Errorf("Unexpected attr group and keys: %s %v", o.SlogOptions.attrGroup, o.SlogOptions.attrKeys)
+		}
+		if len(o.attrs) != 3 || o.attrs[0].String() != "db=primary" || o.attrs[1].String() != "az=a" || o.attrs[2].String() != "region=us" {
+			t.Errorf("Unexpected attrs: %v", o.attrs)
+		}
+		if o.nPlusOneThreshold != 0 || o.explain.threshold != 500*time.Millisecond || o.badConnLevel != LevelInfo {
+			t.Errorf("Unexpected options: %v %v %v", o.nPlusOneThreshold, o.explain.threshold, o.badConnLevel)
+		}
+		begin := o.DriverOptions.ConnOptions.Begin
+		if begin.Start.Msg != "Conn.Begin Start" || begin.Complete.Msg != "Conn.Begin Complete" || begin.Complete.Level != LevelWarn {
+			t.Errorf("Unexpected options of Conn.Begin: %+v", begin)
+		}
+		if begin.Error.Msg != "Conn.Begin" {
+			t.Errorf("Expected the default message but got %s", begin.Error.Msg)
+		}
+		if next := o.DriverOptions.ConnOptions.RowsOptions.Next; next.Complete.Level != LevelDebug-2 || next.Error.Msg != "Rows Error" {
+			t.Errorf("Unexpected options of Rows.Next: %+v", next)
+		}
+		if rowsClose := o.DriverOptions.ConnOptions.RowsOptions.Close; rowsClose.Complete.Level != LevelError {
+			t.Errorf("Unexpected level of Rows.Close: %v", rowsClose.Complete.Level)
+		}
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		t.Parallel()
+		c, err := LoadConfig(strings.NewReader(`{"query_fingerprint": false, "detect_leaks": false, "semconv": false, "tracing": true}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		o := newOptions("sqlite3", append([]Option{QueryFingerprint(true), DetectLeaks(true), SemConv(true)}, c.Options()...)...)
+		if o.queryOptions.fingerprint || o.leak.enabled || o.semconv || o.queryOptions.semconv {
+			t.Errorf("Expected the features to be disabled: %v %v %v", o.queryOptions.fingerprint, o.leak.enabled, o.semconv)
+		}
+		if !o.tracing {
+			t.Error("Expected tracing to be enabled")
+		}
+		if o := newOptions("sqlite3", append([]Option{QueryFingerprint(true)}, (&Config{}).Options()...)...); !o.queryOptions.fingerprint {
+			t.Error("Expected the omitted boolean to keep the previous option")
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+		lv, d, f := LevelDebug-2, DurationString, false
+		c := Config{Level: &lv, Duration: &d, Tracing: &f, LeakAge: ConfigDuration(time.Minute), Steps: map[Step]*StepConfig{
+			StepTxCommit: {Error: &EventConfig{Level: &lv}},
+		}}
+		b, err := json.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := `{"level":"TRACE+2","duration":"string","tracing":false,"leak_age":"1m0s","steps":{"Tx.Commit":{"error":{"level":"TRACE+2"}}}}`
+		if string(b) != expected {
+			t.Errorf("Expected %s but got %s", expected, b)
+		}
+		loaded, err := LoadConfig(strings.NewReader(string(b)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *loaded.Level != lv || *loaded.Duration != d || loaded.Tracing == nil || *loaded.Tracing || loaded.LeakAge != c.LeakAge || *loaded.Steps[StepTxCommit].Error.Level != lv {
+			t.Errorf("Unexpected config: %+v", loaded)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		for _, tc := range []struct {
+			json     string
+			expected string
+		}{
+			{`{"level": "LOUD"}`, `unknown level: "LOUD"`},
+			{`{"duration": "minutes"}`, `unknown duration type`},
+			{`{"args": "mask"}`, `unknown args mode: "mask"`},
+			{`{"leak_age": "1"}`, `missing unit in duration`},
+			{`{"levl": "INFO"}`, `unknown field "levl"`},
+			{`{"format": "xml"}`, `format "xml": expected json or text`},
+			{`{"dialect": "db2"}`, `dialect "db2" is not registered`},
+			{`{"steps": {"Conn.Begin": {}, "Conn.Start": {}}}`, `unknown step "Conn.Start"`},
+		} {
+			_, err := LoadConfig(strings.NewReader(tc.json))
+			if !errors.Is(err, ErrInvalidConfig) || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("%s: expected %q but got %v", tc.json, tc.expected, err)
+			}
+		}
+	})
+}
@@ -3,7 +3,6 @@ package sqlslog
 import (
 	"context"
 	"database/sql/driver"
-	"errors"
 	"fmt"
 	"log/slog"
 )
@@ -18,6 +17,8 @@ type connOptions struct {
 
 	Close StepOptions
 
+	IsValid StepOptions
+
 	Prepare        StepOptions
 	PrepareContext StepOptions
 	StmtIDKey      string
@@ -46,6 +47,11 @@ func defaultConnOptions(driverName string, msgb StepEventMsgBuilder) *connOption
 
 		Close: *defaultStepOptions(msgb, StepConnClose, LevelInfo),
 
+		// database/sql calls IsValid whenever a connection is returned to the pool,
+		// so it's lower than LevelVerbose not to be logged by default except for invalid connections.
+		IsValid: *newStepOptions(msgb, StepConnIsValid,
+			LevelVerbose-2*defaultSlogLevelDiff, LevelError, LevelVerbose-defaultSlogLevelDiff),
+
 		Prepare:        *defaultStepOptions(msgb, StepConnPrepare, LevelInfo),
 		PrepareContext: *defaultStepOptions(msgb, StepConnPrepareContext, LevelInfo),
 		StmtIDKey:      StmtIDKeyDefault,
@@ -54,9 +60,9 @@ func defaultConnOptions(driverName string, msgb StepEventMsgBuilder) *connOption
 		ResetSession: *defaultStepOptions(msgb, StepConnResetSession, LevelTrace),
 		Ping:         *defaultStepOptions(msgb, StepConnPing, LevelTrace),
 
-		ExecContext: *defaultStepOptions(msgb, StepConnExecContext, LevelInfo, ConnExecContextErrorHandler(driverName)),
+		ExecContext: *defaultStepOptions(msgb, StepConnExecContext, LevelInfo),
 
-		QueryContext: *defaultStepOptions(msgb, StepConnQueryContext, LevelInfo, ConnQueryContextErrorHandler(driverName)),
+		QueryContext: *defaultStepOptions(msgb, StepConnQueryContext, LevelInfo),
 		RowsOptions:  rowsOptions,
 	}
 }
@@ -76,6 +82,7 @@ func wrapConn(original driver.Conn, logger *stepLogger, options *connOptions) dr
 		return original
 	}
 
+	logger = logger.withOpenConn(logger.register(openObjectConn, ""))
 	connWrapper := connWrapper{original: original, logger: logger, options: options}
 	if cwc, ok := original.(connWithContext); ok {
 		connWrapper2 := connWithContextWrapper{connWrapper, cwc}
@@ -137,20 +144,22 @@ func (c *connWrapper) Begin() (driver.Tx, error) {
 	}
 	lg := c.logger
 	if attr != nil {
-		lg = lg.With(*attr)
+		lg = lg.withID(*attr)
 	}
 	return wrapTx(origTx, lg, c.options.TxOptions), nil
 }
 
 // Close implements driver.Conn.
 func (c *connWrapper) Close() error {
+	c.logger.conn.unregister()
 	return ignoreAttr(c.logger.StepWithoutContext(&c.options.Close, withNilAttr(c.original.Close)))
 }
 
 // Prepare implements driver.Conn.
 func (c *connWrapper) Prepare(query string) (driver.Stmt, error) {
 	var origStmt driver.Stmt
-	attr, err := c.logger.With(slog.String("query", query)).StepWithoutContext(&c.options.Prepare, func() (*slog.Attr, error) {
+	qlg := c.logger.withQuery(query)
+	attr, err := qlg.withLoggedQuery().StepWithoutContext(&c.options.Prepare, func() (*slog.Attr, error) {
 		var err error
 		origStmt, err = c.original.Prepare(query)
 		if err != nil {
@@ -162,9 +171,9 @@ func (c *connWrapper) Prepare(query string) (driver.Stmt, error) {
 	if err != nil {
 		return nil, err
 	}
-	lg := c.logger
+	lg := qlg
 	if attr != nil {
-		lg = lg.With(*attr)
+		lg = lg.withID(*attr)
 	}
 	return wrapStmt(origStmt, lg, c.options.StmtOptions), nil
 }
@@ -172,12 +181,25 @@ func (c *connWrapper) Prepare(query string) (driver.Stmt, error) {
 // IsValid implements driver.Validator.
 func (c *connWrapper) IsValid() bool {
 	// https://cs.opensource.google/go/go/+/master:src/database/sql/sql.go;l=618-621
-	if v, ok := c.original.(driver.Validator); ok {
-		return v.IsValid()
+	v, ok := c.original.(driver.Validator)
+	if !ok {
+		return true
 	}
-	return true
+	valid := true
+	_ = ignoreAttr(c.logger.StepWithoutContext(&c.options.IsValid, func() (*slog.Attr, error) {
+		valid = v.IsValid()
+		if !valid {
+			return nil, errInvalidConn
+		}
+		return nil, nil
+	}))
+	return valid
 }
 
+// errInvalidConn is the error for the Error event of Conn.IsValid which returns false.
+// database/sql discards the connection in the same way as driver.ErrBadConn.
+var errInvalidConn = fmt.Errorf("connection is invalid: %w", driver.ErrBadConn)
+
 type connWithContext interface {
 	driver.Conn
 	driver.ExecerContext
@@ -233,10 +255,8 @@ func (c *connWithContextWrapper) Ping(ctx context.Context) error {
 // ExecContext implements driver.ExecerContext.
 func (c *connWithContextWrapper) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
 	var result driver.Result
-	lg := c.logger.With(
-		slog.String("query", query),
-		slog.String("args", fmt.Sprintf("%+v", args)),
-	)
+	qlg := c.logger.withQuery(query)
+	lg := qlg.withLoggedQuery().withArgs(args)
 	err := ignoreAttr(lg.Step(ctx, &c.options.ExecContext, func() (*slog.Attr, error) {
 		var err error
 		result, err = c.originalConn.ExecContext(ctx, query, args)
@@ -245,16 +265,15 @@ func (c *connWithContextWrapper) ExecContext(ctx context.Context, query string,
 	if err != nil {
 		return nil, err
 	}
+	trackResult(ctx, result)
 	return result, nil
 }
 
 // QueryContext implements driver.QueryerContext.
 func (c *connWithContextWrapper) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
 	var rows driver.Rows
-	lg := c.logger.With(
-		slog.String("query", query),
-		slog.String("args", fmt.Sprintf("%+v", args)),
-	)
+	qlg := c.logger.withQuery(query)
+	lg := qlg.withLoggedQuery().withArgs(args)
 	err := ignoreAttr(lg.Step(ctx, &c.options.QueryContext, func() (*slog.Attr, error) {
 		var err error
 		rows, err = c.originalConn.QueryContext(ctx, query, args)
@@ -263,13 +282,14 @@ func (c *connWithContextWrapper) QueryContext(ctx context.Context, query string,
 	if err != nil {
 		return nil, err
 	}
-	return wrapRows(rows, c.logger, c.options.RowsOptions), nil
+	return wrapRows(rows, qlg.withContext(ctx), c.options.RowsOptions), nil
 }
 
 // PrepareContext implements driver.ConnPrepareContext.
 func (c *connWithContextWrapper) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
 	var stmt driver.Stmt
-	attr, err := c.logger.With(slog.String("query", query)).Step(ctx, &c.options.PrepareContext, func() (*slog.Attr, error) {
+	qlg := c.logger.withQuery(query)
+	attr, err := qlg.withLoggedQuery().Step(ctx, &c.options.PrepareContext, func() (*slog.Attr, error) {
 		var err error
 		stmt, err = c.originalConn.PrepareContext(ctx, query)
 		if err != nil {
@@ -281,9 +301,9 @@ func (c *connWithContextWrapper) PrepareContext(ctx context.Context, query strin
 	if err != nil {
 		return nil, err
 	}
-	lg := c.logger
+	lg := qlg
 	if attr != nil {
-		lg = lg.With(*attr)
+		lg = lg.withID(*attr)
 	}
 	return wrapStmt(stmt, lg, c.options.StmtOptions), nil
 }
@@ -303,49 +323,31 @@ func (c *connWithContextWrapper) BeginTx(ctx context.Context, opts driver.TxOpti
 	if err != nil {
 		return nil, err
 	}
-	lg := c.logger
+	lg := c.logger.withContext(ctx)
 	if attr != nil {
-		lg = lg.With(*attr)
+		lg = lg.withID(*attr)
 	}
 	return wrapTx(tx, lg, c.options.TxOptions), nil
 }
 
 const driverNameMysql = "mysql"
 
+// ConnExecContextErrorHandler returns the error handler for Conn.ExecContext of the dialect for the driver name.
+// It returns nil if the dialect is not found or it doesn't have the handler.
 func ConnExecContextErrorHandler(driverName string) func(err error) (bool, []slog.Attr) {
-	switch driverName {
-	case driverNameMysql:
-		return func(err error) (bool, []slog.Attr) {
-			if err == nil {
-				return true, nil
-			}
-			// https://pkg.go.dev/database/sql/driver#ErrSkip
-			if errors.Is(err, driver.ErrSkip) {
-				return true, []slog.Attr{slog.Bool("skip", true)}
-			}
-			return false, nil
-		}
-	default:
-		return nil
+	if d := LookupDialect(driverName); d != nil {
+		return d.ErrorHandlers[StepConnExecContext]
 	}
+	return nil
 }
 
+// ConnQueryContextErrorHandler returns the error handler for Conn.QueryContext of the dialect for the driver name.
+// It returns nil if the dialect is not found or it doesn't have the handler.
 func ConnQueryContextErrorHandler(driverName string) func(err error) (bool, []slog.Attr) {
-	switch driverName {
-	case driverNameMysql:
-		return func(err error) (bool, []slog.Attr) {
-			if err == nil {
-				return true, nil
-			}
-			// https://pkg.go.dev/database/sql/driver#ErrSkip
-			if errors.Is(err, driver.ErrSkip) {
-				return true, []slog.Attr{slog.Bool("skip", true)}
-			}
-			return false, nil
-		}
-	default:
-		return nil
+	if d := LookupDialect(driverName); d != nil {
+		return d.ErrorHandlers[StepConnQueryContext]
 	}
+	return nil
 }
 
 type connNvcWrapper struct {
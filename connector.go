@@ -3,9 +3,9 @@ package sqlslog
 import (
 	"context"
 	"database/sql/driver"
-	"errors"
 	"io"
 	"log/slog"
+	"sync"
 )
 
 type connectorOptions struct {
@@ -24,12 +24,19 @@ type connector struct {
 	original driver.Connector
 	logger   *stepLogger
 	options  *connectorOptions
+
+	// closed is closed when the connector is closed by sql.DB.Close.
+	closed    chan struct{}
+	closeOnce sync.Once
 }
 
-var _ driver.Connector = (*connector)(nil)
+var (
+	_ driver.Connector = (*connector)(nil)
+	_ io.Closer        = (*connector)(nil)
+)
 
 func wrapConnector(original driver.Connector, logger *stepLogger, options *connectorOptions) driver.Connector {
-	return &connector{original: original, logger: logger, options: options}
+	return &connector{original: original, logger: logger, options: options, closed: make(chan struct{})}
 }
 
 // Connect implements driver.Connector.
@@ -44,7 +51,7 @@ func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
 		return nil, err
 	}
 
-	return wrapConn(origConn, c.logger, c.options.ConnOptions), nil
+	return wrapConn(origConn, c.logger.withConnector(unwrapConnector(c.original)), c.options.ConnOptions), nil
 }
 
 // Driver implements driver.Connector.
@@ -52,36 +59,34 @@ func (c *connector) Driver() driver.Driver {
 	return c.original.Driver()
 }
 
+// Close implements io.Closer.
+// sql.DB.Close calls it, and it cancels the running explanations and closes the original connector
+// if it implements io.Closer.
+func (c *connector) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	c.logger.explainer.close()
+	if closer, ok := c.original.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 // ConnectorConnectErrorHandler returns a function that handles errors from driver.Connector.Connect.
+// It's the ConnectErrorHandler of the dialect for the driver name.
 // The function returns a boolean indicating completion and a slice of slog.Attr.
 //
+// # For MySQL:
+// If err is nil, it returns true and a slice of slog.Attr{slog.Bool("success", true)}.
+// If err wraps driver.ErrBadConn, it returns true and a slice of slog.Attr{slog.Bool("success", false)}.
+// Otherwise, it returns false and nil.
+//
 // # For Postgres:
 // If err is nil, it returns true and a slice of slog.Attr{slog.Bool("success", true)}.
-// If err is io.EOF, it returns true and a slice of slog.Attr{slog.Bool("success", false)}.
+// If err wraps io.EOF, it returns true and a slice of slog.Attr{slog.Bool("success", false)}.
 // Otherwise, it returns false and nil.
 func ConnectorConnectErrorHandler(driverName string) func(err error) (bool, []slog.Attr) {
-	switch driverName {
-	case "mysql":
-		return func(err error) (bool, []slog.Attr) {
-			if err == nil {
-				return true, []slog.Attr{slog.Bool("success", true)}
-			}
-			if err.Error() == "driver: bad connection" {
-				return true, []slog.Attr{slog.Bool("success", false)}
-			}
-			return false, nil
-		}
-	case "postgres":
-		return func(err error) (bool, []slog.Attr) {
-			if err == nil {
-				return true, []slog.Attr{slog.Bool("success", true)}
-			}
-			if errors.Is(err, io.EOF) {
-				return true, []slog.Attr{slog.Bool("success", false)}
-			}
-			return false, nil
-		}
-	default:
-		return nil
+	if d := LookupDialect(driverName); d != nil {
+		return d.ConnectErrorHandler
 	}
+	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql/driver"
 	"errors"
+	"fmt"
 	"io"
 	"testing"
 )
@@ -54,13 +55,17 @@ func TestConnectorConnectErrorHandler(t *testing.T) {
 	t.Run("mysql driver: bad connection", func(t *testing.T) {
 		t.Parallel()
 		errHandler := ConnectorConnectErrorHandler("mysql")
-		complete, attrs := errHandler(errors.New("driver: bad connection"))
+		complete, attrs := errHandler(fmt.Errorf("connect: %w", driver.ErrBadConn))
 		if !complete {
 			t.Fatal("Expected true")
 		}
 		if attrs == nil {
 			t.Fatal("Expected non-nil")
 		}
+		// The errors which only have the same message are not handled.
+		if complete, _ := errHandler(errors.New("driver: bad connection")); complete { // nolint:err113
+			t.Fatal("Expected false")
+		}
 	})
 	t.Run("sqlite3", func(t *testing.T) {
 		t.Parallel()
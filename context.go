@@ -0,0 +1,86 @@
+package sqlslog
+
+import (
+	"context"
+	"log/slog"
+)
+
+type contextKey int
+
+const (
+	contextKeyLogging contextKey = iota + 1
+	contextKeyQueryTracker
+)
+
+// loggingContext is the logging options for the steps running under a context.
+type loggingContext struct {
+	level    *Level
+	disabled bool
+	attrs    []slog.Attr
+}
+
+func loggingContextFrom(ctx context.Context) *loggingContext {
+	if ctx == nil {
+		return nil
+	}
+	if v, ok := ctx.Value(contextKeyLogging).(*loggingContext); ok {
+		return v
+	}
+	return nil
+}
+
+func withLoggingContext(ctx context.Context, f func(*loggingContext)) context.Context {
+	var r loggingContext
+	if v := loggingContextFrom(ctx); v != nil {
+		r = *v
+		r.attrs = append([]slog.Attr{}, v.attrs...)
+	}
+	f(&r)
+	return context.WithValue(ctx, contextKeyLogging, &r)
+}
+
+// WithLevel returns a context with the level for every step running under the context.
+// The level of Start event is lower than lv by 4 and the level of Complete event is lv,
+// like [StepOptions.SetLevel]. The level of Error event is not changed.
+// The rows and the transactions created under the context are also affected.
+func WithLevel(ctx context.Context, lv Level) context.Context {
+	return withLoggingContext(ctx, func(c *loggingContext) { c.level = &lv })
+}
+
+// WithoutLogging returns a context which suppresses Start and Complete events
+// of every step running under the context. Error events are still logged.
+// The rows and the transactions created under the context are also affected.
+func WithoutLogging(ctx context.Context) context.Context {
+	return withLoggingContext(ctx, func(c *loggingContext) { c.disabled = true })
+}
+
+// WithAttrs returns a context with the attributes which are added to the events
+// of every step running under the context.
+// The rows and the transactions created under the context are also affected.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	return withLoggingContext(ctx, func(c *loggingContext) { c.attrs = append(c.attrs, attrs...) })
+}
+
+// levels returns the levels of Start and Complete events overridden by the context.
+func (c *loggingContext) levels(start, complete Level) (Level, Level) {
+	if c == nil || c.level == nil {
+		return start, complete
+	}
+	return *c.level - defaultSlogLevelDiff, *c.level
+}
+
+// suppressed returns true if Start and Complete events are suppressed.
+func (c *loggingContext) suppressed() bool {
+	return c != nil && c.disabled
+}
+
+func (c *loggingContext) args() []interface{} {
+	if c == nil || len(c.attrs) == 0 {
+		return nil
+	}
+	r := make([]interface{}, len(c.attrs))
+	for i, attr := range c.attrs {
+		r[i] = attr
+	}
+	return r
+}
@@ -0,0 +1,78 @@
+package sqlslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestLoggingContext(t *testing.T) {
+	t.Parallel()
+
+	newLogger := func() (*stepLogger, *bytes.Buffer) {
+		buf := bytes.NewBuffer(nil)
+		handler := NewTextHandler(buf, &slog.HandlerOptions{Level: LevelDebug, ReplaceAttr: removeTimeAndDurationForTest})
+		return newStepLogger(slog.New(handler), defaultStepLoggerOptions()), buf
+	}
+	stepOptions := defaultStepOptions(StepEventMsgWithoutEventName, StepConnPing, LevelInfo)
+
+	t.Run("WithLevel", func(t *testing.T) {
+		t.Parallel()
+		logger, buf := newLogger()
+		ctx := WithLevel(context.Background(), LevelTrace)
+		if _, err := logger.Step(ctx, stepOptions, func() (*slog.Attr, error) { return nil, nil }); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() > 0 {
+			t.Fatalf("Unexpected log: %q", buf.String())
+		}
+	})
+
+	t.Run("WithoutLogging", func(t *testing.T) {
+		t.Parallel()
+		logger, buf := newLogger()
+		ctx := WithoutLogging(context.Background())
+		if _, err := logger.Step(ctx, stepOptions, func() (*slog.Attr, error) { return nil, nil }); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() > 0 {
+			t.Fatalf("Unexpected log: %q", buf.String())
+		}
+		if _, err := logger.Step(ctx, stepOptions, func() (*slog.Attr, error) { return nil, errors.New("unexpected") }); err == nil {
+			t.Fatal("Expected error")
+		}
+		if expected := "level=ERROR msg=Conn.Ping error=unexpected\n"; buf.String() != expected {
+			t.Fatalf("Expected %q, got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("WithAttrs and derived rows", func(t *testing.T) {
+		t.Parallel()
+		logger, buf := newLogger()
+		ctx := WithAttrs(context.Background(), slog.String("req_id", "r1"))
+		ctx = WithAttrs(WithLevel(ctx, LevelInfo), slog.Int("n", 1))
+		rows := wrapRows(
+			&mockRowsNextResultSet{error: io.EOF},
+			logger.withContext(ctx),
+			defaultRowsOptions(StepEventMsgWithoutEventName),
+		)
+		if err := rows.Next(nil); !errors.Is(err, io.EOF) {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := "level=DEBUG msg=Rows.Next req_id=r1 n=1\n" +
+			"level=INFO msg=Rows.Next req_id=r1 n=1 eof=true\n"
+		if buf.String() != expected {
+			t.Fatalf("Expected %q, got %q", expected, buf.String())
+		}
+	})
+}
+
+func TestLoggingContextFromNil(t *testing.T) {
+	t.Parallel()
+	if loggingContextFrom(nil) != nil { //nolint:staticcheck
+		t.Fatal("Expected nil")
+	}
+}
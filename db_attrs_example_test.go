@@ -0,0 +1,24 @@
+package sqlslog_test
+
+import (
+	"context"
+
+	sqlslog "github.com/akm/sql-slog"
+)
+
+func ExampleName() {
+	dsn := "postgres://localhost:5432/orders"
+	ctx := context.TODO()
+	db, logger, _ := sqlslog.Open(ctx, "mock", dsn,
+		sqlslog.Name("replica-2"),
+		sqlslog.UseDialect(sqlslog.DialectPostgres),
+		sqlslog.DSNAttrs(true),
+		sqlslog.LogReplaceAttr(removeTimeAndDuration), // for testing
+	)
+	defer db.Close()
+	logger.InfoContext(ctx, "Hello, World!")
+
+	// Output:
+	// level=INFO msg=Open db=replica-2 db.system=postgres db.name=orders server.address=localhost server.port=5432 driver=mock dsn=postgres://localhost:5432/orders
+	// level=INFO msg="Hello, World!"
+}
@@ -0,0 +1,64 @@
+package sqlslog
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+// DBStatsInterval sets the interval to log the statistics of the connection pool given by [sql.DB.Stats].
+// sqlslog logs a "DB.Stats" event with the statistics and their deltas from the previous event
+// at [LevelInfo], or at [LevelWarn] when the number of the connections waited for grows.
+// The reporter stops when the DB is closed.
+// If it's zero or negative, the statistics are not logged.
+// The default is zero.
+func DBStatsInterval(d time.Duration) Option {
+	return func(o *options) { o.stepLoggerOptions.dbStatsInterval = d }
+}
+
+const (
+	dbStatsMsg           = "DB.Stats"
+	dbStatsLevel         = LevelInfo
+	dbStatsPressureLevel = LevelWarn
+)
+
+// reportDBStats logs the statistics of db at the interval until done is closed.
+func (x *stepLogger) reportDBStats(db *sql.DB, done <-chan struct{}) {
+	ticker := time.NewTicker(x.options.dbStatsInterval)
+	defer ticker.Stop()
+	var prev sql.DBStats
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			stats := db.Stats()
+			level := dbStatsLevel
+			if stats.WaitCount > prev.WaitCount {
+				level = dbStatsPressureLevel
+			}
+			x.LogAttrs(context.Background(), slog.Level(level), dbStatsMsg, x.options.dbStatsAttrs(stats, prev)...)
+			prev = stats
+		}
+	}
+}
+
+func (o *stepLoggerOptions) dbStatsAttrs(stats, prev sql.DBStats) []slog.Attr {
+	return []slog.Attr{
+		slog.Int("max_open_connections", stats.MaxOpenConnections),
+		slog.Int("open_connections", stats.OpenConnections),
+		slog.Int("in_use", stats.InUse),
+		slog.Int("idle", stats.Idle),
+		slog.Int64("wait_count", stats.WaitCount),
+		slog.Int64("wait_count_delta", stats.WaitCount-prev.WaitCount),
+		o.durationAttrWithKey("wait_duration", stats.WaitDuration),
+		o.durationAttrWithKey("wait_duration_delta", stats.WaitDuration-prev.WaitDuration),
+		slog.Int64("max_idle_closed", stats.MaxIdleClosed),
+		slog.Int64("max_idle_closed_delta", stats.MaxIdleClosed-prev.MaxIdleClosed),
+		slog.Int64("max_idle_time_closed", stats.MaxIdleTimeClosed),
+		slog.Int64("max_idle_time_closed_delta", stats.MaxIdleTimeClosed-prev.MaxIdleTimeClosed),
+		slog.Int64("max_lifetime_closed", stats.MaxLifetimeClosed),
+		slog.Int64("max_lifetime_closed_delta", stats.MaxLifetimeClosed-prev.MaxLifetimeClosed),
+	}
+}
@@ -0,0 +1,72 @@
+package sqlslog
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDBStatsInterval(t *testing.T) {
+	t.Parallel()
+	buf := &syncBuffer{}
+	factory := New("mock", "", LogWriter(buf), DBStatsInterval(time.Millisecond))
+	db, err := factory.Open(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := waitForLog(t, buf, "msg=DB.Stats")
+	if !strings.Contains(out, "level=INFO msg=DB.Stats max_open_connections=0 open_connections=0 in_use=0 idle=0 wait_count=0 wait_count_delta=0 wait_duration=0s") {
+		t.Errorf("Unexpected log: %q", out)
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Wait for the reporter which may be logging to stop
+	time.Sleep(10 * time.Millisecond)
+	n := len(buf.String())
+	time.Sleep(20 * time.Millisecond)
+	if len(buf.String()) != n {
+		t.Errorf("Expected no logs after Close, got %q", buf.String()[n:])
+	}
+}
+
+func TestDBStatsAttrs(t *testing.T) {
+	t.Parallel()
+	prev := sql.DBStats{WaitCount: 2, WaitDuration: time.Second, MaxIdleClosed: 1, MaxIdleTimeClosed: 1, MaxLifetimeClosed: 1}
+	stats := sql.DBStats{
+		MaxOpenConnections: 10, OpenConnections: 5, InUse: 3, Idle: 2,
+		WaitCount: 5, WaitDuration: 3 * time.Second, MaxIdleClosed: 2, MaxIdleTimeClosed: 4, MaxLifetimeClosed: 1,
+	}
+	var parts []string
+	o := defaultStepLoggerOptions()
+	for _, attr := range o.dbStatsAttrs(stats, prev) {
+		parts = append(parts, attr.String())
+	}
+	expected := "max_open_connections=10 open_connections=5 in_use=3 idle=2 " +
+		"wait_count=5 wait_count_delta=3 wait_duration=3s wait_duration_delta=2s " +
+		"max_idle_closed=2 max_idle_closed_delta=1 max_idle_time_closed=4 max_idle_time_closed_delta=3 " +
+		"max_lifetime_closed=1 max_lifetime_closed_delta=0"
+	if actual := strings.Join(parts, " "); actual != expected {
+		t.Errorf("Expected %q but got %q", expected, actual)
+	}
+}
+
+func TestConnectorClose(t *testing.T) {
+	t.Parallel()
+	c := wrapConnector(&mockConnectorForWrapConnector{}, newStepLogger(slog.Default(), defaultStepLoggerOptions()), nil).(*connector)
+	for range 2 {
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case <-c.closed:
+	default:
+		t.Fatal("Expected closed")
+	}
+}
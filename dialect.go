@@ -0,0 +1,367 @@
+package sqlslog
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Dialect is the set of the behaviors specific to a database and its drivers.
+// The dialect is detected from the driver name or the driver type by the registered dialects,
+// and it can be specified by [UseDialect]. Users can add dialects by [RegisterDialect].
+type Dialect struct {
+	// Name is the name of the dialect such as "postgres".
+	Name string
+	// System is the database system such as "postgres" which is logged as db.system by [DSNAttrs].
+	System string
+	// DriverNames are the driver names to detect the dialect such as "postgres" and "pgx".
+	DriverNames []string
+	// DriverTypes are the type names of the drivers to detect the dialect such as "*pq.Driver".
+	// They are compared with the name formatted by %T.
+	DriverTypes []string
+
+	// Syntax is the SQL syntax which includes the placeholder style.
+	Syntax SQLSyntax
+	// ExplainPrefix is prepended to the query to explain it such as "EXPLAIN ".
+	// If it's empty, the queries are not explained even if [ExplainSlowQueries] is set.
+	ExplainPrefix string
+	// DSNParser parses the DSN for [DSNAttrs]. If it's nil, the DSN is parsed as URL.
+	DSNParser func(dsn string) DSNInfo
+	// ErrorExtractor extracts the information from the errors of the drivers.
+	// It is used before [DefaultErrorExtractor] when [ErrorEnrichment] is enabled.
+	ErrorExtractor ErrorExtractor
+
+	// ErrorHandlers are the error handlers of the steps which are used unless the options set them.
+	ErrorHandlers map[Step]func(error) (bool, []slog.Attr)
+	// OpenErrorHandler is the error handler for Driver.Open returned by [DriverOpenErrorHandler].
+	// It is not used by default because it logs the failed connections as completed.
+	OpenErrorHandler func(error) (bool, []slog.Attr)
+	// ConnectErrorHandler is the error handler for Connector.Connect returned by [ConnectorConnectErrorHandler].
+	// It is not used by default because it logs the failed connections as completed.
+	ConnectErrorHandler func(error) (bool, []slog.Attr)
+}
+
+// String returns the name of the dialect.
+func (d *Dialect) String() string { return d.Name }
+
+// DialectGeneric is the dialect for the unknown drivers. It is not registered.
+var DialectGeneric = &Dialect{
+	Name:          "generic",
+	Syntax:        SQLSyntaxGeneric,
+	ExplainPrefix: "EXPLAIN ",
+}
+
+var (
+	// DialectSQLite3 is the dialect for github.com/mattn/go-sqlite3.
+	DialectSQLite3 = &Dialect{
+		Name:           "sqlite3",
+		System:         "sqlite",
+		DSNParser:      parseSQLiteDSN,
+		DriverNames:    []string{"sqlite3"},
+		DriverTypes:    []string{"*sqlite3.SQLiteDriver"},
+		Syntax:         SQLSyntaxSQLite,
+		ExplainPrefix:  "EXPLAIN QUERY PLAN ",
+		ErrorExtractor: SQLiteErrorExtractor,
+	}
+
+	// DialectModerncSQLite is the dialect for modernc.org/sqlite.
+	DialectModerncSQLite = &Dialect{
+		Name:           "sqlite",
+		System:         "sqlite",
+		DSNParser:      parseSQLiteDSN,
+		DriverNames:    []string{"sqlite"},
+		DriverTypes:    []string{"*sqlite.Driver"},
+		Syntax:         SQLSyntaxSQLite,
+		ExplainPrefix:  "EXPLAIN QUERY PLAN ",
+		ErrorExtractor: SQLiteErrorExtractor,
+	}
+
+	// DialectPostgres is the dialect for github.com/lib/pq.
+	DialectPostgres = &Dialect{
+		Name:                "postgres",
+		System:              "postgres",
+		DSNParser:           parsePostgresDSN,
+		DriverNames:         []string{"postgres", "postgresql", "cloudsqlpostgres"},
+		DriverTypes:         []string{"*pq.Driver"},
+		Syntax:              SQLSyntaxPostgres,
+		ExplainPrefix:       "EXPLAIN ",
+		ErrorExtractor:      PostgresErrorExtractor,
+		OpenErrorHandler:    postgresOpenErrorHandler,
+		ConnectErrorHandler: postgresConnectErrorHandler,
+	}
+
+	// DialectPgx is the dialect for github.com/jackc/pgx/v5/stdlib and github.com/jackc/pgx/v4/stdlib.
+	DialectPgx = &Dialect{
+		Name:           "pgx",
+		System:         "postgres",
+		DSNParser:      parsePostgresDSN,
+		DriverNames:    []string{"pgx", "pgx/v4", "pgx/v5"},
+		DriverTypes:    []string{"*stdlib.Driver"},
+		Syntax:         SQLSyntaxPostgres,
+		ExplainPrefix:  "EXPLAIN ",
+		ErrorExtractor: PostgresErrorExtractor,
+	}
+
+	// DialectMySQL is the dialect for github.com/go-sql-driver/mysql.
+	DialectMySQL = &Dialect{
+		Name:           driverNameMysql,
+		System:         driverNameMysql,
+		DSNParser:      parseMySQLDSN,
+		DriverNames:    []string{driverNameMysql},
+		DriverTypes:    []string{"*mysql.MySQLDriver"},
+		Syntax:         SQLSyntaxMySQL,
+		ExplainPrefix:  "EXPLAIN ",
+		ErrorExtractor: MySQLErrorExtractor,
+		ErrorHandlers: map[Step]func(error) (bool, []slog.Attr){
+			StepConnExecContext:  mysqlSkipErrorHandler,
+			StepConnQueryContext: mysqlSkipErrorHandler,
+		},
+		ConnectErrorHandler: mysqlConnectErrorHandler,
+	}
+
+	// DialectSQLServer is the dialect for github.com/microsoft/go-mssqldb.
+	// The queries are not explained because SQL Server has no EXPLAIN statement.
+	DialectSQLServer = &Dialect{
+		Name:           "sqlserver",
+		System:         "sqlserver",
+		DriverNames:    []string{"sqlserver", "mssql", "azuresql"},
+		DriverTypes:    []string{"*mssql.Driver"},
+		Syntax:         SQLSyntaxGeneric,
+		ErrorExtractor: SQLServerErrorExtractor,
+	}
+
+	// DialectOracle is the dialect for github.com/sijms/go-ora and github.com/godror/godror.
+	// The queries are not explained because EXPLAIN PLAN doesn't return the plan as rows.
+	DialectOracle = &Dialect{
+		Name:           "oracle",
+		System:         "oracle",
+		DriverNames:    []string{"oracle", "godror"},
+		DriverTypes:    []string{"*go_ora.OracleDriver", "*godror.drv"},
+		Syntax:         SQLSyntaxGeneric,
+		ErrorExtractor: OracleErrorExtractor,
+	}
+)
+
+var dialects = struct {
+	sync.RWMutex
+	list []*Dialect
+}{
+	list: []*Dialect{
+		DialectSQLite3,
+		DialectModerncSQLite,
+		DialectPostgres,
+		DialectPgx,
+		DialectMySQL,
+		DialectSQLServer,
+		DialectOracle,
+	},
+}
+
+// RegisterDialect registers the dialect to be detected from the driver names and the driver types.
+// The dialects registered later take precedence over the ones registered earlier and the presets.
+func RegisterDialect(d *Dialect) {
+	dialects.Lock()
+	defer dialects.Unlock()
+	dialects.list = append([]*Dialect{d}, dialects.list...)
+}
+
+// LookupDialect returns the registered dialect for the driver name, or nil if it's not found.
+// The driver name is compared case-insensitively.
+func LookupDialect(driverName string) *Dialect {
+	dialects.RLock()
+	defer dialects.RUnlock()
+	for _, d := range dialects.list {
+		for _, name := range d.DriverNames {
+			if strings.EqualFold(name, driverName) {
+				return d
+			}
+		}
+	}
+	return nil
+}
+
+// DetectDialect returns the registered dialect for the type of the driver, or nil if it's not found.
+// The drivers wrapped by sqlslog are unwrapped.
+func DetectDialect(drv driver.Driver) *Dialect {
+	switch w := drv.(type) {
+	case *driverWrapper:
+		drv = w.original
+	case *driverContextWrapper:
+		drv = w.driverWrapper.original
+	}
+	typeName := fmt.Sprintf("%T", drv)
+	dialects.RLock()
+	defer dialects.RUnlock()
+	for _, d := range dialects.list {
+		for _, name := range d.DriverTypes {
+			if name == typeName {
+				return d
+			}
+		}
+	}
+	return nil
+}
+
+// dialectOf returns the registered dialect for the driver name, or DialectGeneric if it's not found.
+// The dialects of the other drivers are detected from the type of the driver when the database is opened.
+func dialectOf(driverName string) *Dialect {
+	if d := LookupDialect(driverName); d != nil {
+		return d
+	}
+	return DialectGeneric
+}
+
+// UseDialect sets the dialect instead of the one detected from the driver.
+// The error handlers of the steps in the dialect are used for the steps whose ErrorHandler is not set
+// by the other options, regardless of the order of the options.
+// If d is nil, no dialect-specific behavior is used as [DialectGeneric].
+func UseDialect(d *Dialect) Option {
+	return func(o *options) {
+		if d == nil {
+			d = DialectGeneric
+		}
+		o.setDialect(d)
+		o.dialectGiven = true
+	}
+}
+
+// detectDialect replaces the options of the factory with a copy for the dialect detected from the type of drv
+// unless the dialect is given by UseDialect or registered for the driver name.
+// The copy takes effect for the databases opened by the factory as [Factory.Update].
+// The caller must lock f.mu.
+func (f *Factory) detectDialect(drv driver.Driver) {
+	o := f.options
+	if o.dialectGiven || LookupDialect(f.driverName) != nil {
+		return
+	}
+	d := DetectDialect(drv)
+	if d == nil || d == o.dialect {
+		return
+	}
+	updated := o.clone()
+	updated.setDialect(d)
+	updated.fillDialectErrorHandlers()
+	o.publish(updated)
+	f.options = updated
+}
+
+func (o *options) setDialect(d *Dialect) {
+	o.stepLoggerOptions.dialect = d
+	o.stepLoggerOptions.queryOptions.syntax = d.Syntax
+}
+
+// fillDialectErrorHandlers sets the error handlers of the dialect to the steps without ErrorHandler.
+func (o *options) fillDialectErrorHandlers() {
+	d := o.stepLoggerOptions.dialect
+	if d == nil {
+		return
+	}
+	for _, step := range o.stepOptions() {
+		if h, ok := d.ErrorHandlers[step.step]; ok && step.ErrorHandler == nil {
+			step.ErrorHandler = h
+		}
+	}
+}
+
+func mysqlSkipErrorHandler(err error) (bool, []slog.Attr) {
+	if err == nil {
+		return true, nil
+	}
+	// https://pkg.go.dev/database/sql/driver#ErrSkip
+	if errors.Is(err, driver.ErrSkip) {
+		return true, []slog.Attr{slog.Bool("skip", true)}
+	}
+	return false, nil
+}
+
+func mysqlConnectErrorHandler(err error) (bool, []slog.Attr) {
+	if err == nil {
+		return true, []slog.Attr{slog.Bool("success", true)}
+	}
+	if errors.Is(err, driver.ErrBadConn) {
+		return true, []slog.Attr{slog.Bool("success", false)}
+	}
+	return false, nil
+}
+
+func postgresOpenErrorHandler(err error) (bool, []slog.Attr) {
+	if err == nil {
+		return true, []slog.Attr{slog.Bool("success", true)}
+	}
+	if errors.Is(err, io.EOF) {
+		return true, []slog.Attr{slog.Bool("success", false)}
+	}
+	return false, nil
+}
+
+func postgresConnectErrorHandler(err error) (bool, []slog.Attr) {
+	if err == nil {
+		return true, []slog.Attr{slog.Bool("success", true)}
+	}
+	if errors.Is(err, io.EOF) {
+		return true, []slog.Attr{slog.Bool("success", false)}
+	}
+	return false, nil
+}
+
+// SQLServerErrorExtractor is the extractor for the errors with Number field like github.com/microsoft/go-mssqldb.Error.
+func SQLServerErrorExtractor(err error) (ErrorInfo, bool) {
+	v := structValue(err)
+	if !v.IsValid() || intField(v, "Number") == nil {
+		return ErrorInfo{}, false
+	}
+	number := *intField(v, "Number")
+	return ErrorInfo{Code: strconv.FormatInt(number, 10), Class: classifySQLServerError(number)}, true
+}
+
+// https://learn.microsoft.com/en-us/sql/relational-databases/errors-events/database-engine-events-and-errors
+func classifySQLServerError(number int64) ErrorClass {
+	switch number {
+	case 515, 547, 2601, 2627:
+		return ErrorClassConstraintViolation
+	case 1205:
+		return ErrorClassDeadlock
+	case 1222:
+		return ErrorClassTimeout
+	case 3960:
+		return ErrorClassSerializationFailure
+	default:
+		return ""
+	}
+}
+
+// OracleErrorExtractor is the extractor for the errors with ErrCode field like github.com/sijms/go-ora/v2/network.OracleError
+// and the errors with Code() int method like github.com/godror/godror.OraErr.
+func OracleErrorExtractor(err error) (ErrorInfo, bool) {
+	var code int64
+	if v, ok := err.(interface{ Code() int }); ok { // nolint:errorlint
+		code = int64(v.Code())
+	} else if v := structValue(err); v.IsValid() && intField(v, "ErrCode") != nil {
+		code = *intField(v, "ErrCode")
+	}
+	if code == 0 {
+		return ErrorInfo{}, false
+	}
+	return ErrorInfo{Code: fmt.Sprintf("ORA-%05d", code), Class: classifyOracleError(code)}, true
+}
+
+// https://docs.oracle.com/en/error-help/db/
+func classifyOracleError(code int64) ErrorClass {
+	switch code {
+	case 1, 1400, 2290, 2291, 2292:
+		return ErrorClassConstraintViolation
+	case 60:
+		return ErrorClassDeadlock
+	case 8177:
+		return ErrorClassSerializationFailure
+	case 1013, 30006:
+		return ErrorClassTimeout
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,318 @@
+package sqlslog
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+func TestLookupDialect(t *testing.T) {
+	t.Parallel()
+	tests := map[string]*Dialect{
+		"postgres":  DialectPostgres,
+		"pgx":       DialectPgx,
+		"pgx/v5":    DialectPgx,
+		"mysql":     DialectMySQL,
+		"sqlite3":   DialectSQLite3,
+		"sqlite":    DialectModerncSQLite,
+		"sqlserver": DialectSQLServer,
+		"godror":    DialectOracle,
+		"Postgres":  DialectPostgres,
+		"unknown":   nil,
+	}
+	for name, expected := range tests {
+		if actual := LookupDialect(name); actual != expected {
+			t.Errorf("%s: expected %v, got %v", name, expected, actual)
+		}
+	}
+}
+
+func TestDialectSyntax(t *testing.T) {
+	t.Parallel()
+	tests := map[string]SQLSyntax{
+		"postgres": SQLSyntaxPostgres,
+		"pgx":      SQLSyntaxPostgres,
+		"mysql":    SQLSyntaxMySQL,
+		"sqlite3":  SQLSyntaxSQLite,
+		"sqlite":   SQLSyntaxSQLite,
+		"unknown":  SQLSyntaxGeneric,
+	}
+	for name, expected := range tests {
+		if actual := newOptions(name).queryOptions.syntax; actual != expected {
+			t.Errorf("%s: expected %v, got %v", name, expected, actual)
+		}
+	}
+}
+
+type mockDialectDriver struct{}
+
+func (*mockDialectDriver) Open(string) (driver.Conn, error) { return nil, errors.New("unimplemented") }
+
+type mockDialectContextDriver struct {
+	mockDialectDriver
+}
+
+func (*mockDialectContextDriver) OpenConnector(string) (driver.Connector, error) {
+	return nil, errors.New("unimplemented")
+}
+
+func TestDetectDialect(t *testing.T) {
+	t.Parallel()
+	d := &Dialect{
+		Name:        "mock",
+		DriverTypes: []string{"*sqlslog.mockDialectDriver", "*sqlslog.mockDialectContextDriver"},
+		Syntax:      SQLSyntaxPostgres,
+		ErrorHandlers: map[Step]func(error) (bool, []slog.Attr){
+			StepConnQueryContext: func(error) (bool, []slog.Attr) { return true, []slog.Attr{slog.String("by", "dialect")} },
+		},
+	}
+	RegisterDialect(d)
+	if !slices.Contains(sql.Drivers(), "mock-dialect") {
+		sql.Register("mock-dialect", &mockDialectDriver{})
+	}
+
+	t.Run("driver", func(t *testing.T) {
+		t.Parallel()
+		if actual := DetectDialect(&mockDialectDriver{}); actual != d {
+			t.Errorf("Expected %v, got %v", d, actual)
+		}
+	})
+	t.Run("wrapped driver", func(t *testing.T) {
+		t.Parallel()
+		drv := wrapDriver(&mockDialectDriver{}, &stepLogger{}, defaultDriverOptions("mock-dialect", StepEventMsgWithoutEventName))
+		if actual := DetectDialect(drv); actual != d {
+			t.Errorf("Expected %v, got %v", d, actual)
+		}
+	})
+	t.Run("wrapped driver context", func(t *testing.T) {
+		t.Parallel()
+		drv := wrapDriver(&mockDialectContextDriver{}, &stepLogger{}, defaultDriverOptions("mock-dialect", StepEventMsgWithoutEventName))
+		if _, ok := drv.(*driverContextWrapper); !ok {
+			t.Fatalf("Expected *driverContextWrapper, got %T", drv)
+		}
+		if actual := DetectDialect(drv); actual != d {
+			t.Errorf("Expected %v, got %v", d, actual)
+		}
+	})
+	t.Run("unknown driver", func(t *testing.T) {
+		t.Parallel()
+		if actual := DetectDialect(&mockDriverForDsnConnector{}); actual != nil {
+			t.Errorf("Expected nil, got %v", actual)
+		}
+	})
+	t.Run("detected on open", func(t *testing.T) {
+		t.Parallel()
+		f := New("mock-dialect", "", LogWriter(io.Discard))
+		if f.options.dialect != DialectGeneric {
+			t.Errorf("Expected %v before open, got %v", DialectGeneric, f.options.dialect)
+		}
+		original := f.options
+		db, err := f.Open(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		if f.options.dialect != d {
+			t.Errorf("Expected %v, got %v", d, f.options.dialect)
+		}
+		if f.options.queryOptions.syntax != SQLSyntaxPostgres {
+			t.Errorf("Expected %v, got %v", SQLSyntaxPostgres, f.options.queryOptions.syntax)
+		}
+		if _, attrs := f.options.DriverOptions.ConnOptions.QueryContext.ErrorHandler(nil); len(attrs) != 1 || attrs[0].Value.String() != "dialect" {
+			t.Errorf("Expected the error handler of the dialect, got %v", attrs)
+		}
+		if original.dialect != DialectGeneric || original.DriverOptions.ConnOptions.QueryContext.ErrorHandler != nil {
+			t.Error("Expected the options before open not to be modified")
+		}
+		if original.DriverOptions.ConnOptions.QueryContext.current().ErrorHandler == nil {
+			t.Error("Expected the error handler of the dialect to take effect at runtime")
+		}
+	})
+	t.Run("error handler given on open", func(t *testing.T) {
+		t.Parallel()
+		f := New("mock-dialect", "", LogWriter(io.Discard), ConnQueryContext(func(o *StepOptions) {
+			o.ErrorHandler = func(error) (bool, []slog.Attr) { return true, []slog.Attr{slog.String("by", "user")} }
+		}))
+		db, err := f.Open(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		if f.options.dialect != d {
+			t.Errorf("Expected %v, got %v", d, f.options.dialect)
+		}
+		if _, attrs := f.options.DriverOptions.ConnOptions.QueryContext.ErrorHandler(nil); len(attrs) != 1 || attrs[0].Value.String() != "user" {
+			t.Errorf("Expected the error handler given by the option, got %v", attrs)
+		}
+	})
+	t.Run("given dialect on open", func(t *testing.T) {
+		t.Parallel()
+		f := New("mock-dialect", "", LogWriter(io.Discard), UseDialect(DialectMySQL))
+		db, err := f.Open(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		if f.options.dialect != DialectMySQL {
+			t.Errorf("Expected %v, got %v", DialectMySQL, f.options.dialect)
+		}
+	})
+	t.Run("unregistered driver name", func(t *testing.T) {
+		t.Parallel()
+		if o := newOptions("no-such-driver"); o.dialect != DialectGeneric {
+			t.Errorf("Expected %v, got %v", DialectGeneric, o.dialect)
+		}
+	})
+}
+
+func TestUseDialect(t *testing.T) {
+	t.Parallel()
+	t.Run("mysql to postgres", func(t *testing.T) {
+		t.Parallel()
+		o := newOptions("mysql", UseDialect(DialectPostgres))
+		if o.DriverOptions.ConnOptions.ExecContext.ErrorHandler != nil {
+			t.Error("Expected the error handler of mysql to be removed")
+		}
+		if o.DriverOptions.ConnOptions.QueryContext.ErrorHandler != nil {
+			t.Error("Expected the error handler of mysql to be removed")
+		}
+		if o.queryOptions.syntax != SQLSyntaxPostgres {
+			t.Errorf("Expected %v, got %v", SQLSyntaxPostgres, o.queryOptions.syntax)
+		}
+		if o.DriverOptions.ConnOptions.RowsOptions.Next.ErrorHandler == nil {
+			t.Error("Expected the error handler of Rows.Next to be kept")
+		}
+	})
+	t.Run("unknown to mysql", func(t *testing.T) {
+		t.Parallel()
+		o := newOptions("unknown", UseDialect(DialectMySQL))
+		complete, attrs := o.DriverOptions.ConnOptions.ExecContext.ErrorHandler(driver.ErrSkip)
+		if !complete || len(attrs) != 1 {
+			t.Errorf("Expected ErrSkip to be handled, got %v %v", complete, attrs)
+		}
+	})
+	t.Run("error handler given before", func(t *testing.T) {
+		t.Parallel()
+		o := newOptions("unknown", ConnExecContext(func(o *StepOptions) {
+			o.ErrorHandler = func(error) (bool, []slog.Attr) { return false, nil }
+		}), UseDialect(DialectMySQL))
+		if complete, _ := o.DriverOptions.ConnOptions.ExecContext.ErrorHandler(driver.ErrSkip); complete {
+			t.Error("Expected the error handler given by the option to be kept")
+		}
+		if o.DriverOptions.ConnOptions.QueryContext.ErrorHandler == nil {
+			t.Error("Expected the error handler of the dialect for the other step")
+		}
+	})
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+		o := newOptions("mysql", UseDialect(nil))
+		if o.dialect != DialectGeneric || o.queryOptions.syntax != SQLSyntaxGeneric {
+			t.Errorf("Expected %v, got %v", DialectGeneric, o.dialect)
+		}
+		if o.DriverOptions.ConnOptions.ExecContext.ErrorHandler != nil {
+			t.Error("Expected no error handler of the dialect")
+		}
+	})
+}
+
+func TestConnectorConnectErrorHandlerBadConn(t *testing.T) {
+	t.Parallel()
+	complete, attrs := ConnectorConnectErrorHandler("mysql")(driver.ErrBadConn)
+	if !complete || attrs == nil {
+		t.Errorf("Expected driver.ErrBadConn to be handled, got %v %v", complete, attrs)
+	}
+}
+
+type mockSQLServerError struct {
+	Number  int32
+	Message string
+}
+
+func (e mockSQLServerError) Error() string { return e.Message }
+
+type mockGoOraError struct {
+	ErrCode int
+	ErrMsg  string
+}
+
+func (e *mockGoOraError) Error() string { return e.ErrMsg }
+
+type mockGodrorError struct{ code int }
+
+func (e *mockGodrorError) Error() string { return "ORA error" }
+func (e *mockGodrorError) Code() int     { return e.code }
+
+func TestDialectErrorExtractors(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name      string
+		extractor ErrorExtractor
+		err       error
+		expected  ErrorInfo
+		ok        bool
+	}{
+		{
+			name:      "sqlserver unique",
+			extractor: SQLServerErrorExtractor,
+			err:       mockSQLServerError{Number: 2627, Message: "Violation of UNIQUE KEY constraint"},
+			expected:  ErrorInfo{Code: "2627", Class: ErrorClassConstraintViolation},
+			ok:        true,
+		},
+		{
+			name:      "sqlserver deadlock",
+			extractor: SQLServerErrorExtractor,
+			err:       mockSQLServerError{Number: 1205},
+			expected:  ErrorInfo{Code: "1205", Class: ErrorClassDeadlock},
+			ok:        true,
+		},
+		{
+			name:      "sqlserver other error",
+			extractor: SQLServerErrorExtractor,
+			err:       errors.New("other"),
+		},
+		{
+			name:      "go-ora unique",
+			extractor: OracleErrorExtractor,
+			err:       &mockGoOraError{ErrCode: 1, ErrMsg: "ORA-00001: unique constraint violated"},
+			expected:  ErrorInfo{Code: "ORA-00001", Class: ErrorClassConstraintViolation},
+			ok:        true,
+		},
+		{
+			name:      "godror serialization failure",
+			extractor: OracleErrorExtractor,
+			err:       &mockGodrorError{code: 8177},
+			expected:  ErrorInfo{Code: "ORA-08177", Class: ErrorClassSerializationFailure},
+			ok:        true,
+		},
+		{
+			name:      "oracle other error",
+			extractor: OracleErrorExtractor,
+			err:       errors.New("other"),
+		},
+		{
+			name:      "postgres",
+			extractor: PostgresErrorExtractor,
+			err:       &mockPgError{Code: "23505"},
+			expected:  ErrorInfo{SQLState: "23505", Class: ErrorClassConstraintViolation},
+			ok:        true,
+		},
+		{
+			name:      "postgres for sqlite error",
+			extractor: PostgresErrorExtractor,
+			err:       &mockSQLiteError{Code: 19, ExtendedCode: 2067},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			actual, ok := tc.extractor(tc.err)
+			if ok != tc.ok || actual != tc.expected {
+				t.Errorf("Expected %+v %v, got %+v %v", tc.expected, tc.ok, actual, ok)
+			}
+		})
+	}
+}
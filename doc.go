@@ -31,14 +31,137 @@ sqlslog has 6 log levels: [LevelVerbose], [LevelTrace], [LevelDebug], [LevelInfo
 
 A [Step] is a logical operation in the database driver, such as a query, a ping, a prepare, etc.
 An [Event] is an event that occurs during a [Step], such as [EventStart], [EventError], and [EventComplete].
+Steps which fail with context.Canceled or a timeout are logged as [EventCanceled] or [EventTimeout] instead of [EventError].
 A [StepOptions] is a set of options for logging a [Step] and has [EventOptions] for each event.
 sqlslog provides a way to customize the log message and log [Level] for each step event.
 You can customize them by using functions that take [StepOptions] and return [Option], like [ConnPrepareContext] or [StmtQueryContext].
+You can also customize the steps matching a glob such as "Rows.*" or a [StepCategory] such as "lifecycle" at once by [Steps].
 
 # DefaultStepEventMsgBuilder
 
-The default step event message builder is [StepEventMsgWithEventName].
-You can change the default step event message builder by calling [SetStepEventMsgBuilder].
+The default step event message builder is [StepEventMsgWithoutEventName].
+You can change the step event message builder of a logger by [StepEventMsg] option
+with [StepEventMsgWithEventName], [StepEventMsgDotted], [StepEventMsgLowercase] or your own function.
+[SetStepEventMsgBuilder] which changes the default for all the loggers is deprecated.
+
+# Context
+
+You can change the logging behavior for the steps running under a context
+by using [WithLevel], [WithoutLogging] and [WithAttrs].
+The rows and the transactions created under the context are also affected.
+
+# N+1 query detection
+
+[TrackQueries] returns a context which counts the queries by fingerprint running under it.
+When the count of a fingerprint reaches the threshold set by [NPlusOneThreshold],
+sqlslog logs an "N+1 suspected" event with the fingerprint, the count and the call site.
+
+# Query budget
+
+[Budget] returns the number of the queries, the total database time, the rows read and the rows affected
+in a context given by [TrackQueries]. [LogBudget] logs them at the end of a request,
+and [BudgetMiddleware] does it for each HTTP request with [BudgetLimits].
+
+# Duplicate query detection
+
+In a context given by [TrackQueries], sqlslog also counts the queries by fingerprint and args.
+When the same query runs with the same args as many times as the threshold set by [DuplicateQueryThreshold],
+sqlslog logs a "Duplicate query" event with the count and the wasted time.
+[DuplicateQueries] returns all of them to find missing caches.
+
+# EXPLAIN for slow queries
+
+sqlslog can run EXPLAIN for SELECT queries slower than the threshold set by [ExplainSlowQueries]
+on a separate connection, and log the plan as an Explain event of the step such as "Conn.QueryContext Explain"
+linked by op_id. The running explanations are canceled when the DB is closed.
+The queries with the same fingerprint are explained at most once in the interval set by [ExplainInterval].
+
+# Leak detection
+
+sqlslog can warn about Rows, Stmts and Txs which are garbage-collected without being closed,
+and Rows and Txs which stay open longer than [LeakAge], with the call site which created them.
+You can enable it by calling [DetectLeaks] function.
+
+# Debug endpoint
+
+[DebugHandler] returns an [net/http.Handler] like [net/http/pprof] which lists the open connections,
+transactions, statements and rows with their IDs, ages and queries.
+You can enable the registry for it by calling [DebugRegistry] function.
+
+# Recent queries
+
+sqlslog can keep the last queries in memory by [RecentQueries] option.
+[Factory.RecentQueries] returns them, and [Factory.DumpRecentQueries] writes them
+for a panic handler or a SIGQUIT hook.
+
+# Connection pool statistics
+
+sqlslog can log [sql.DBStats] of the DB with their deltas at the interval set by [DBStatsInterval].
+The event is logged at WARN level when the number of the connections waited for grows.
+
+# Bad connections
+
+Error events of the steps which fail with driver.ErrBadConn have bad_conn=true and the level set by [BadConnLevel].
+Conn.IsValid which returns false is also logged in the same way.
+Conn.IsValid is not logged otherwise by default because database/sql calls it very often.
+You can change it by [ConnIsValid].
+
+# Error enrichment
+
+sqlslog can add sqlstate, error_code and error_class to Error events by calling [ErrorEnrichment] function.
+They are extracted from the errors of the well-known drivers without importing them.
+You can add extractors for other drivers by [ErrorExtractors].
+
+# Dialect
+
+sqlslog has a [Dialect] for each database, which bundles the SQL syntax including the placeholder style,
+the EXPLAIN syntax, the error extractor and the error handlers of the steps.
+The dialect is detected from the driver name or the driver type by the presets such as
+[DialectPostgres], [DialectPgx], [DialectMySQL], [DialectSQLite3], [DialectSQLServer] and [DialectOracle].
+You can specify the dialect by calling [UseDialect] function and add your own dialect by calling [RegisterDialect].
+The error handlers of the dialect are used only for the steps whose ErrorHandler is not set by the options.
+
+# Database attributes
+
+You can add attributes to all the logs of a database by calling [Name] and [Attrs] functions
+to distinguish the databases such as the primary and the read replicas.
+[DSNAttrs] adds db.system, db.name, server.address and server.port derived from the dialect and the DSN.
+
+# Attribute keys
+
+The attributes of the logs by sqlslog can collide with the ones of the application such as duration.
+You can put them into a group by calling [AttrGroup] function and rename each key by calling [RenameAttr] function.
+They don't affect the logs by the logger returned by [Open].
+
+# OpenTelemetry semantic conventions
+
+[SemConv] names the attributes by the OpenTelemetry semantic conventions for databases
+such as db.system, db.query.text, db.operation.name, db.collection.name, db.response.returned_rows,
+server.address and error.type, and logs the durations in seconds as float.
+It doesn't require the OpenTelemetry SDK.
+
+# Args
+
+sqlslog logs the args of queries as they are by default.
+You can redact the values or omit the args by calling [Args] function with [ArgsRedacted] or [ArgsOmitted].
+
+# Environment variables
+
+[FromEnv] returns the options from the environment variables such as SQLSLOG_LEVEL, SQLSLOG_FORMAT and
+SQLSLOG_STEP_ROWS_NEXT_LEVEL, so that you can tune the logging in each environment without changing the code.
+
+# Config
+
+[Config] is the declarative configuration with JSON tags which covers the messages and the levels of
+the events of each step, the duration, the ID keys, the handler and the features.
+You can load it from JSON by calling [LoadConfig] and pass the options returned by [Config.Options].
+
+# Runtime reconfiguration
+
+[Factory.Update] changes the messages and the levels of the steps, the threshold of [ExplainSlowQueries]
+and the rate of [Sampling] of the databases opened by the factory without reopening them.
+[Factory.Reset] discards the previous updates and rebuilds them from the options given by [New].
+[Factory.ConfigHandler] returns an [net/http.Handler] to read and modify them in JSON of [Config].
 
 # Duration
 
@@ -55,7 +178,33 @@ Tracking IDs are generated by the ID generator function. The default ID generato
 You can change the ID generator function by calling [IDGenerator] with functions created by [RandIntIDGenerator] or
 [RandReadIDGenerator] with [IDGenErrorSuppressor].
 
+# Query fingerprint
+
+sqlslog can log a query fingerprint, a short stable ID of the normalized query,
+to aggregate logs by statement shape. The normalization depends on the placeholder style of the driver.
+You can enable it by calling [QueryFingerprint] function.
+
+# Query name
+
+sqlslog can parse the name annotation in the leading comment of queries like `-- name: ListAuthors :many`
+generated by sqlc, and log them as query_name and query_kind.
+You can enable it by calling [QueryName] function, and remove the comment from logged queries by calling [StripQueryName].
+
+# Operation and tables
+
+sqlslog can classify each query into an [Operation] such as [OperationSelect] and [OperationDDL]
+and detect the tables which the query touches on a best-effort basis.
+You can log them by calling [QueryOperation] and [QueryTables] functions,
+and change the levels for an operation by calling [OperationLevel] or [StepOptions.SetOperationLevel].
+
+# Tracing
+
+sqlslog can run each step in a [runtime/trace] region with [runtime/pprof] labels
+so that `go tool trace` and CPU profiles show which SQL statements cost what.
+You can enable it by calling [Tracing] function.
+
 [*sql.DB]: https://pkg.go.dev/database/sql#DB
+[sql.DBStats]: https://pkg.go.dev/database/sql#DBStats
 [*slog.Logger]: https://pkg.go.dev/log/slog#Logger
 [slog.Handler]: https://pkg.go.dev/log/slog#Handler
 */
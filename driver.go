@@ -3,7 +3,6 @@ package sqlslog
 import (
 	"database/sql/driver"
 	"log/slog"
-	"strings"
 )
 
 type driverOptions struct {
@@ -73,9 +72,9 @@ func (w *driverWrapper) Open(dsn string) (driver.Conn, error) {
 	if err != nil {
 		return nil, err
 	}
-	lg := w.logger
+	lg := w.logger.withConnector(&dsnConnector{dsn: dsn, driver: w.original})
 	if attr != nil {
-		lg = lg.With(*attr)
+		lg = lg.withID(*attr)
 	}
 
 	return wrapConn(origConn, lg, w.options.ConnOptions), nil
@@ -110,32 +109,23 @@ func (w *driverContextWrapper) OpenConnector(dsn string) (driver.Connector, erro
 	}
 	lg := w.logger
 	if attr != nil {
-		lg = lg.With(*attr)
+		lg = lg.withID(*attr)
 	}
 
 	return wrapConnector(origConnector, lg, w.options.ConnectorOptions), nil
 }
 
 // DriverOpenErrorHandler returns a function that handles errors from driver.Driver.Open.
+// It's the OpenErrorHandler of the dialect for the driver name.
 // The function returns a boolean indicating completion and a slice of slog.Attr.
 //
 // # For Postgres:
 // If err is nil, it returns true and a slice of slog.Attr{slog.Bool("success", true)}.
-// If err is io.EOF, it returns true and a slice of slog.Attr{slog.Bool("success", false)}.
+// If err wraps io.EOF, it returns true and a slice of slog.Attr{slog.Bool("success", false)}.
 // Otherwise, it returns false and nil.
 func DriverOpenErrorHandler(driverName string) func(err error) (bool, []slog.Attr) {
-	switch driverName {
-	case "postgres":
-		return func(err error) (bool, []slog.Attr) {
-			if err == nil {
-				return true, []slog.Attr{slog.Bool("success", true)}
-			}
-			if strings.ToUpper(err.Error()) == "EOF" {
-				return true, []slog.Attr{slog.Bool("success", false)}
-			}
-			return false, nil
-		}
-	default:
-		return nil
+	if d := LookupDialect(driverName); d != nil {
+		return d.OpenErrorHandler
 	}
+	return nil
 }
@@ -0,0 +1,111 @@
+package sqlslog
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// DuplicateQueryThreshold sets the number of the identical queries with the same args
+// to report a "Duplicate query" event in the context given by [TrackQueries].
+// Such queries are redundant repeats which may be cached.
+// The queries which differ only in their literals are not duplicates but an N+1 pattern reported by [NPlusOneThreshold].
+// If it's zero or negative, the event is never logged.
+// The default is DuplicateQueryThresholdDefault.
+func DuplicateQueryThreshold(n int) Option {
+	return func(o *options) { o.stepLoggerOptions.duplicateQueryThreshold = n }
+}
+
+// DuplicateQueryThresholdDefault is the default threshold for [DuplicateQueryThreshold].
+const DuplicateQueryThresholdDefault = 3
+
+const (
+	duplicateQueryMsg   = "Duplicate query"
+	duplicateQueryLevel = LevelWarn
+	wastedKey           = "wasted"
+)
+
+// DuplicateQuery is the summary of the identical queries with the same args
+// which run under a context given by [TrackQueries].
+type DuplicateQuery struct {
+	Fingerprint string        // Fingerprint of the query.
+	Query       string        // Query as logged.
+	Args        string        // Args as logged.
+	Count       int           // Number of the runs.
+	Wasted      time.Duration // Total duration of the runs except the first one.
+}
+
+// DuplicateQueries returns the queries which run more than once with the same args under the context.
+// They are sorted by the wasted time in descending order.
+// The context must be given by [TrackQueries]. Otherwise, it returns nil.
+func DuplicateQueries(ctx context.Context) []DuplicateQuery {
+	tracker := queryTrackerFrom(ctx)
+	if tracker == nil {
+		return nil
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	var r []DuplicateQuery
+	for _, d := range tracker.duplicates {
+		if d.Count > 1 {
+			r = append(r, *d)
+		}
+	}
+	sort.SliceStable(r, func(i, j int) bool { return r[i].Wasted > r[j].Wasted })
+	return r
+}
+
+// recordDuplicate counts the query with the given key and returns the summary of the query.
+func (t *queryTracker) recordDuplicate(key string, newQuery func() DuplicateQuery, d time.Duration) DuplicateQuery {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r, ok := t.duplicates[key]
+	if !ok {
+		v := newQuery()
+		r = &v
+		t.duplicates[key] = r
+	} else {
+		r.Wasted += d
+		t.budget.Duplicates++
+		t.budget.Wasted += d
+	}
+	r.Count++
+	return *r
+}
+
+// trackDuplicate records the query with the args and logs a duplicate query event if needed.
+func (x *stepLogger) trackDuplicate(ctx context.Context, tracker *queryTracker, d time.Duration) {
+	fingerprint := x.query.Fingerprint()
+	// The query is compared as it is because the fingerprint ignores the literals.
+	// The hash is calculated from the actual args even if they are redacted.
+	dup := tracker.recordDuplicate(x.query.text+"/"+argsHash(fmt.Sprintf("%+v", x.args)), func() DuplicateQuery {
+		return DuplicateQuery{Fingerprint: fingerprint, Query: x.query.logged, Args: x.argsText()}
+	}, d)
+	if x.options.duplicateQueryThreshold <= 0 || dup.Count != x.options.duplicateQueryThreshold {
+		return
+	}
+	x.Log(ctx, slog.Level(duplicateQueryLevel), duplicateQueryMsg, append([]interface{}{
+		slog.String(x.options.queryOptions.fingerprintKey, fingerprint),
+		slog.Int(countKey, dup.Count),
+		x.options.durationAttrWithKey(wastedKey, dup.Wasted),
+		slog.String(callSiteKey, callSite()),
+	}, x.queryAttrs()...)...)
+}
+
+// argsText returns the args as logged. It returns an empty string if the args are omitted.
+func (x *stepLogger) argsText() string {
+	if x.options != nil && x.options.argsMode == ArgsOmitted {
+		return ""
+	}
+	return x.formatArgs(x.args)
+}
+
+// argsHash returns a short hash of the args.
+func argsHash(args string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(args))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
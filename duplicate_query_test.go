@@ -0,0 +1,73 @@
+package sqlslog
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDuplicateQueries(t *testing.T) {
+	t.Parallel()
+	buf := bytes.NewBuffer(nil)
+	opts := newOptions("sqlite3", DuplicateQueryThreshold(3), NPlusOneThreshold(0))
+	handler := NewTextHandler(buf, &slog.HandlerOptions{Level: LevelWarn})
+	logger := newStepLogger(slog.New(handler), opts.stepLoggerOptions)
+	conn := wrapConn(newMockErrConn(nil), logger, opts.DriverOptions.ConnOptions)
+	execer := conn.(driver.ExecerContext)
+
+	ctx := TrackQueries(context.Background())
+	for i := range 4 {
+		// Same args for every run
+		if _, err := execer.ExecContext(ctx, "DELETE FROM users WHERE id = ?", []driver.NamedValue{{Ordinal: 1, Value: 1}}); err != nil {
+			t.Fatal(err)
+		}
+		// Different args for each run
+		if _, err := execer.ExecContext(ctx, "DELETE FROM users WHERE id = ?", []driver.NamedValue{{Ordinal: 1, Value: i + 2}}); err != nil {
+			t.Fatal(err)
+		}
+		// Same fingerprint but different literals for each run
+		if _, err := execer.ExecContext(ctx, "DELETE FROM users WHERE id = "+strconv.Itoa(i+10), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 line, got %q", buf.String())
+	}
+	for _, s := range []string{
+		`msg="Duplicate query"`,
+		`query="DELETE FROM users WHERE id = ?" args="[{Name: Ordinal:1 Value:1}]"`,
+		"query_fingerprint=" + queryFingerprint(SQLSyntaxSQLite, "DELETE FROM users WHERE id = ?"),
+		"count=3",
+		"wasted=",
+		"duplicate_query_test.go",
+	} {
+		if !strings.Contains(lines[0], s) {
+			t.Errorf("Expected %q in %q", s, lines[0])
+		}
+	}
+	if strings.Count(lines[0], "query=") != 1 {
+		t.Errorf("Expected only one query in %q", lines[0])
+	}
+
+	dups := DuplicateQueries(ctx)
+	if len(dups) != 1 {
+		t.Fatalf("Expected 1 duplicate query, got %+v", dups)
+	}
+	if dups[0].Count != 4 || dups[0].Args != "[{Name: Ordinal:1 Value:1}]" || dups[0].Query != "DELETE FROM users WHERE id = ?" {
+		t.Errorf("Unexpected duplicate query: %+v", dups[0])
+	}
+	report := Budget(ctx)
+	if report.Queries != 12 || report.Duplicates != 3 || report.Wasted != dups[0].Wasted {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	if DuplicateQueries(context.Background()) != nil {
+		t.Error("Expected nil without tracking")
+	}
+}
@@ -1,5 +1,11 @@
 package sqlslog
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
 type DurationType int
 
 const (
@@ -8,8 +14,42 @@ const (
 	DurationMilliSeconds                     // Duration in milliseconds. Durations in log are expressed by slog.Int64
 	DurationGoDuration                       // Values in log are expressed with slog.Duration
 	DurationString                           // Values in log are expressed with slog.String and time.Duration.String
+	DurationSeconds                          // Duration in seconds. Durations in log are expressed by slog.Float64
 )
 
+var durationTypeNames = map[DurationType]string{
+	DurationNanoSeconds:  "ns",
+	DurationMicroSeconds: "us",
+	DurationMilliSeconds: "ms",
+	DurationGoDuration:   "duration",
+	DurationString:       "string",
+	DurationSeconds:      "s",
+}
+
+// String returns the name of the duration type such as "ms".
+func (v DurationType) String() string {
+	if s, ok := durationTypeNames[v]; ok {
+		return s
+	}
+	return fmt.Sprintf("DurationType(%d)", int(v))
+}
+
+// MarshalText implements encoding.TextMarshaler by String.
+func (v DurationType) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// It accepts ns, us, ms, duration, string and s, and nanoseconds, microseconds, milliseconds and seconds.
+func (v *DurationType) UnmarshalText(data []byte) error {
+	r, err := parseDurationType(string(data))
+	if err != nil {
+		return err
+	}
+	*v = r
+	return nil
+}
+
 // Duration is an option to specify duration value in log.
 // The default is DurationNanoSeconds.
 func Duration(v DurationType) Option {
@@ -28,3 +68,25 @@ func DurationKey(key string) Option {
 
 // DurationKeyDefault is the default key for duration value in log.
 const DurationKeyDefault = "duration"
+
+// ErrUnknownDurationType is returned for the unknown names of the duration types.
+var ErrUnknownDurationType = errors.New("unknown duration type, expected ns, us, ms, duration, string or s")
+
+func parseDurationType(s string) (DurationType, error) {
+	switch strings.ToLower(s) {
+	case "ns", "nanoseconds":
+		return DurationNanoSeconds, nil
+	case "us", "microseconds":
+		return DurationMicroSeconds, nil
+	case "ms", "milliseconds":
+		return DurationMilliSeconds, nil
+	case "duration":
+		return DurationGoDuration, nil
+	case "string":
+		return DurationString, nil
+	case "s", "seconds":
+		return DurationSeconds, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownDurationType, s)
+	}
+}
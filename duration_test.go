@@ -64,3 +64,21 @@ func TestDurationString(t *testing.T) {
 		})
 	}
 }
+
+func TestDurationTypeText(t *testing.T) {
+	t.Parallel()
+	for _, v := range []DurationType{DurationNanoSeconds, DurationMicroSeconds, DurationMilliSeconds, DurationGoDuration, DurationString, DurationSeconds} {
+		b, err := v.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual DurationType
+		if err := actual.UnmarshalText(b); err != nil || actual != v {
+			t.Errorf("%s: expected %v but got %v %v", b, v, actual, err)
+		}
+	}
+	var actual DurationType
+	if err := actual.UnmarshalText([]byte("minutes")); err == nil {
+		t.Error("Expected an error")
+	}
+}
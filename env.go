@@ -0,0 +1,203 @@
+package sqlslog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefixDefault is the prefix of the environment variables used by [FromEnv] when the prefix is empty.
+const EnvPrefixDefault = "SQLSLOG"
+
+// ErrInvalidEnv is returned by [FromEnv] for the environment variables with invalid values.
+var ErrInvalidEnv = errors.New("invalid environment variable")
+
+// FromEnv returns the options from the environment variables with the prefix.
+// If prefix is empty, [EnvPrefixDefault] is used. The variables with SQLSLOG prefix are:
+//
+//   - SQLSLOG_LEVEL: the log level for [LogLevel] parsed by [ParseLevel] such as DEBUG and TRACE.
+//   - SQLSLOG_FORMAT: json or text for [HandlerFunc] with [NewJSONHandler] or [NewTextHandler].
+//   - SQLSLOG_ADD_SOURCE: a boolean for [AddSource].
+//   - SQLSLOG_DURATION: ns, us, ms, duration, string or s for [Duration].
+//   - SQLSLOG_DURATION_KEY: the key for [DurationKey].
+//   - SQLSLOG_ATTR_GROUP: the group name for [AttrGroup].
+//   - SQLSLOG_NAME: the name of the database for [Name].
+//   - SQLSLOG_DSN_ATTRS: a boolean for [DSNAttrs].
+//   - SQLSLOG_SEMCONV: a boolean for [SemConv].
+//   - SQLSLOG_SLOW_THRESHOLD: the duration such as 500ms for [ExplainSlowQueries]. It runs EXPLAIN for the slower
+//     SELECT queries on separate connections. SQLSLOG_EXPLAIN_SLOW_QUERIES is an alias.
+//   - SQLSLOG_ARGS: log, redact or omit for [Args].
+//   - SQLSLOG_STEP_<STEP>_LEVEL: the level for [StepOptions.SetLevel] of the step.
+//   - SQLSLOG_STEP_<STEP>_START_LEVEL, SQLSLOG_STEP_<STEP>_ERROR_LEVEL, SQLSLOG_STEP_<STEP>_COMPLETE_LEVEL:
+//     the level of the event of the step.
+//
+// <STEP> is the step name in upper snake case such as ROWS_NEXT for Rows.Next and CONN_EXEC_CONTEXT for Conn.ExecContext.
+//
+// The other variables with SQLSLOG prefix are invalid to catch typos. The other variables with a custom prefix
+// are ignored, so the custom prefix can be shared with the application.
+//
+// FromEnv returns the options of the valid variables and an error which wraps [ErrInvalidEnv]
+// for each invalid variable, so the caller can decide whether to use the options or not.
+func FromEnv(prefix string) ([]Option, error) {
+	return fromEnv(prefix, os.Environ())
+}
+
+func fromEnv(prefix string, environ []string) ([]Option, error) {
+	if prefix == "" {
+		prefix = EnvPrefixDefault
+	}
+	prefix = strings.TrimSuffix(prefix, "_") + "_"
+
+	vars := map[string]string{}
+	for _, kv := range environ {
+		k, v, ok := strings.Cut(kv, "=")
+		if ok && strings.HasPrefix(k, prefix) {
+			vars[strings.TrimPrefix(k, prefix)] = v
+		}
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var opts []Option
+	var errs []error
+	for _, name := range names {
+		opt, err := envOption(name, vars[name])
+		if errors.Is(err, errUnknownEnv) && prefix != EnvPrefixDefault+"_" {
+			// The other variables with the custom prefix such as APP_DATABASE_URL for "APP" prefix are not for sqlslog.
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w %s%s=%q: %w", ErrInvalidEnv, prefix, name, vars[name], err))
+			continue
+		}
+		if opt != nil {
+			opts = append(opts, opt)
+		}
+	}
+	return opts, errors.Join(errs...)
+}
+
+var (
+	errUnknownEnv    = errors.New("unknown variable")
+	errUnknownFormat = errors.New("expected json or text")
+	errUnknownStep   = errors.New("unknown step")
+)
+
+// envOption returns the option for the variable without the prefix.
+// It returns nil without error for the empty values.
+func envOption(name, value string) (Option, error) { // nolint:cyclop,funlen
+	if value == "" {
+		return nil, nil // nolint:nilnil
+	}
+	switch name {
+	case "LEVEL":
+		lv, err := ParseLevel(value)
+		if err != nil {
+			return nil, err
+		}
+		return LogLevel(lv), nil
+	case "FORMAT":
+		switch strings.ToLower(value) {
+		case "json":
+			return HandlerFunc(NewJSONHandler), nil
+		case "text":
+			return HandlerFunc(NewTextHandler), nil
+		default:
+			return nil, errUnknownFormat
+		}
+	case "ADD_SOURCE":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		return AddSource(v), nil
+	case "DURATION":
+		v, err := parseDurationType(value)
+		if err != nil {
+			return nil, err
+		}
+		return Duration(v), nil
+	case "ATTR_GROUP":
+		return AttrGroup(value), nil
+	case "NAME":
+		return Name(value), nil
+	case "DSN_ATTRS":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		return DSNAttrs(v), nil
+	case "SEMCONV":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		return SemConv(v), nil
+	case "DURATION_KEY":
+		return DurationKey(value), nil
+	case "SLOW_THRESHOLD", "EXPLAIN_SLOW_QUERIES":
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, err
+		}
+		return ExplainSlowQueries(d), nil
+	case "ARGS":
+		mode, err := ParseArgsMode(value)
+		if err != nil {
+			return nil, err
+		}
+		return Args(mode), nil
+	}
+	if strings.HasPrefix(name, "STEP_") && strings.HasSuffix(name, "_LEVEL") {
+		return envStepLevelOption(strings.TrimSuffix(strings.TrimPrefix(name, "STEP_"), "_LEVEL"), value)
+	}
+	return nil, errUnknownEnv
+}
+
+// envStepLevelOption returns the option for SQLSLOG_STEP_<STEP>_[START_|ERROR_|COMPLETE_]LEVEL.
+func envStepLevelOption(name, value string) (Option, error) {
+	lv, err := ParseLevel(value)
+	if err != nil {
+		return nil, err
+	}
+	set := func(o *StepOptions) { o.SetLevel(lv) }
+	for _, event := range []struct {
+		suffix string
+		set    func(o *StepOptions)
+	}{
+		{"_START", func(o *StepOptions) { o.Start.Level = lv }},
+		{"_ERROR", func(o *StepOptions) { o.Error.Level = lv }},
+		{"_COMPLETE", func(o *StepOptions) { o.Complete.Level = lv }},
+	} {
+		if s, ok := strings.CutSuffix(name, event.suffix); ok && envSteps[s] != "" {
+			name, set = s, event.set
+			break
+		}
+	}
+	step, ok := envSteps[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", errUnknownStep, name)
+	}
+	return stepOption(step, set), nil
+}
+
+// envSteps is the steps by the names in upper snake case.
+var envSteps = func() map[string]Step {
+	r := map[string]Step{}
+	for _, step := range steps {
+		r[envStepName(step)] = step
+	}
+	return r
+}()
+
+// envStepName returns the step name in upper snake case such as CONN_EXEC_CONTEXT.
+func envStepName(step Step) string {
+	return strings.ToUpper(strings.ReplaceAll(snakeCase(step.String()), ".", "_"))
+}
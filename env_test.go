@@ -0,0 +1,136 @@
+package sqlslog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+		opts, err := fromEnv("", []string{
+			"SQLSLOG_LEVEL=trace",
+			"SQLSLOG_FORMAT=json",
+			"SQLSLOG_DURATION=ms",
+			"SQLSLOG_DURATION_KEY=elapsed",
+			"SQLSLOG_SLOW_THRESHOLD=500ms",
+			"SQLSLOG_ARGS=redact",
+			"SQLSLOG_NAME=replica-2",
+			"SQLSLOG_ATTR_GROUP=sql",
+			"SQLSLOG_DSN_ATTRS=true",
+			"SQLSLOG_SEMCONV=true",
+			"SQLSLOG_STEP_ROWS_NEXT_LEVEL=TRACE",
+			"SQLSLOG_STEP_CONN_EXEC_CONTEXT_ERROR_LEVEL=WARN",
+			"SQLSLOG_ADD_SOURCE=",
+			"OTHER_LEVEL=invalid",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		o := newOptions("sqlite3", opts...)
+		if o.SlogOptions.Level != LevelTrace {
+			t.Errorf("Unexpected level: %v", o.SlogOptions.Level)
+		}
+		if o.stepLoggerOptions.durationType != DurationMilliSeconds || o.stepLoggerOptions.durationKey != "elapsed" {
+			t.Errorf("Unexpected duration: %v %s", o.stepLoggerOptions.durationType, o.stepLoggerOptions.durationKey)
+		}
+		if o.stepLoggerOptions.explain.threshold != 500*time.Millisecond {
+			t.Errorf("Unexpected threshold: %v", o.stepLoggerOptions.explain.threshold)
+		}
+		if !o.stepLoggerOptions.semconv || !o.stepLoggerOptions.queryOptions.semconv {
+			t.Error("Expected semconv to be enabled")
+		}
+		if o.SlogOptions.attrGroup != "sql" {
+			t.Errorf("Unexpected attr group: %s", o.SlogOptions.attrGroup)
+		}
+		if len(o.attrs) != 1 || o.attrs[0].String() != "db=replica-2" || !o.dsnAttrs {
+			t.Errorf("Unexpected attrs: %v %v", o.attrs, o.dsnAttrs)
+		}
+		if o.stepLoggerOptions.argsMode != ArgsRedacted {
+			t.Errorf("Unexpected args mode: %v", o.stepLoggerOptions.argsMode)
+		}
+		if next := o.DriverOptions.ConnOptions.RowsOptions.Next; next.Start.Level != LevelVerbose || next.Complete.Level != LevelTrace {
+			t.Errorf("Unexpected levels of Rows.Next: %v %v", next.Start.Level, next.Complete.Level)
+		}
+		if exec := o.DriverOptions.ConnOptions.ExecContext; exec.Error.Level != LevelWarn || exec.Complete.Level != LevelInfo {
+			t.Errorf("Unexpected levels of Conn.ExecContext: %v %v", exec.Error.Level, exec.Complete.Level)
+		}
+		if o.SlogOptions.handlerFunc == nil {
+			t.Error("Expected handlerFunc")
+		}
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		t.Parallel()
+		opts, err := fromEnv("APP_DB_", []string{"APP_DB_LEVEL=WARN", "APP_DB_URL=postgres://localhost/orders", "SQLSLOG_LEVEL=INFO"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if o := newOptions("sqlite3", opts...); o.SlogOptions.Level != LevelWarn {
+			t.Errorf("Unexpected level: %v", o.SlogOptions.Level)
+		}
+	})
+
+	t.Run("alias", func(t *testing.T) {
+		t.Parallel()
+		opts, err := fromEnv("", []string{"SQLSLOG_EXPLAIN_SLOW_QUERIES=2s"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if o := newOptions("sqlite3", opts...); o.stepLoggerOptions.explain.threshold != 2*time.Second {
+			t.Errorf("Unexpected threshold: %v", o.stepLoggerOptions.explain.threshold)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		opts, err := fromEnv("SQLSLOG", []string{
+			"SQLSLOG_LEVEL=LOUD",
+			"SQLSLOG_FORMAT=xml",
+			"SQLSLOG_DURATION=ms",
+			"SQLSLOG_SLOW_THRESHOLD=500",
+			"SQLSLOG_ARGS=mask",
+			"SQLSLOG_STEP_ROWS_PREV_LEVEL=TRACE",
+			"SQLSLOG_UNKNOWN=1",
+		})
+		if !errors.Is(err, ErrInvalidEnv) || !errors.Is(err, ErrUnknownLevel) || !errors.Is(err, ErrUnknownArgsMode) {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, s := range []string{
+			`SQLSLOG_LEVEL="LOUD"`,
+			`SQLSLOG_FORMAT="xml": expected json or text`,
+			`SQLSLOG_SLOW_THRESHOLD="500"`,
+			`SQLSLOG_ARGS="mask"`,
+			`SQLSLOG_STEP_ROWS_PREV_LEVEL="TRACE": unknown step ROWS_PREV`,
+			`SQLSLOG_UNKNOWN="1": unknown variable`,
+		} {
+			if !strings.Contains(err.Error(), s) {
+				t.Errorf("Expected %q in %q", s, err.Error())
+			}
+		}
+		if len(opts) != 1 {
+			t.Errorf("Expected the option of the valid variable but got %d", len(opts))
+		}
+	})
+}
+
+func TestEnvStepName(t *testing.T) {
+	t.Parallel()
+	for step, expected := range map[Step]string{
+		StepRowsNext:          "ROWS_NEXT",
+		StepRowsNextResultSet: "ROWS_NEXT_RESULT_SET",
+		StepConnExecContext:   "CONN_EXEC_CONTEXT",
+		StepSqlslogOpen:       "OPEN",
+	} {
+		if actual := envStepName(step); actual != expected {
+			t.Errorf("Expected %s but got %s", expected, actual)
+		}
+	}
+	if len(envSteps) != len(steps) {
+		t.Errorf("Expected unique names for %d steps but got %d", len(steps), len(envSteps))
+	}
+}
@@ -0,0 +1,300 @@
+package sqlslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrorClass is the class of the database error.
+type ErrorClass string
+
+const (
+	ErrorClassConstraintViolation  ErrorClass = "constraint_violation"  // Unique, foreign key, not null and check violations.
+	ErrorClassDeadlock             ErrorClass = "deadlock"              // Deadlocks detected by the database.
+	ErrorClassSerializationFailure ErrorClass = "serialization_failure" // Serialization failures which can be retried.
+	ErrorClassTimeout              ErrorClass = "timeout"               // Statement timeouts, lock wait timeouts and context deadlines.
+)
+
+// ErrorInfo is the structured information of the database error.
+type ErrorInfo struct {
+	SQLState string     // SQLSTATE such as 23505.
+	Code     string     // Driver specific error code such as 1062 for MySQL.
+	Class    ErrorClass // Class of the error. It's empty if the error is not classified.
+}
+
+// ErrorExtractor extracts the structured information from the error.
+// It returns false if the error is not supported.
+// The error is each of the errors in the tree of the error returned by the step.
+type ErrorExtractor func(err error) (ErrorInfo, bool)
+
+// ErrorEnrichment sets whether Error events have the attributes extracted from the error:
+// SQLStateKey, ErrorCodeKey and ErrorClassKey.
+// The information is extracted without importing drivers by checking the SQLState() method
+// and the well-known fields by reflection: Code of pq and pgconn, Number and SQLState of mysql,
+// Code and ExtendedCode of sqlite3. See [ErrorExtractors] to add extractors.
+// The default is false.
+func ErrorEnrichment(v bool) Option {
+	return func(o *options) { o.stepLoggerOptions.errorEnrichment = v }
+}
+
+// ErrorExtractors adds extractors which are used before the extractor of the dialect and the default extractor
+// when [ErrorEnrichment] is enabled.
+func ErrorExtractors(extractors ...ErrorExtractor) Option {
+	return func(o *options) {
+		o.stepLoggerOptions.errorExtractors = append(o.stepLoggerOptions.errorExtractors, extractors...)
+	}
+}
+
+const (
+	SQLStateKey   = "sqlstate"    // Key for SQLSTATE of the error.
+	ErrorCodeKey  = "error_code"  // Key for the driver specific error code.
+	ErrorClassKey = "error_class" // Key for the class of the error.
+)
+
+// errorAttrs returns the attributes extracted from the error.
+func (x *stepLogger) errorAttrs(err error) []interface{} {
+	if !x.options.errorEnrichment || err == nil {
+		return nil
+	}
+	info := x.errorInfo(err)
+	var r []interface{}
+	if info.SQLState != "" {
+		r = append(r, slog.String(SQLStateKey, info.SQLState))
+	}
+	if info.Code != "" {
+		r = append(r, slog.String(ErrorCodeKey, info.Code))
+	}
+	if info.Class != "" {
+		r = append(r, slog.String(ErrorClassKey, string(info.Class)))
+	}
+	return r
+}
+
+// errorInfo returns the information extracted from the error by the extractors given by ErrorExtractors,
+// the extractor of the dialect and DefaultErrorExtractor.
+func (x *stepLogger) errorInfo(err error) ErrorInfo {
+	extractors := append([]ErrorExtractor{}, x.options.errorExtractors...)
+	if d := x.options.dialect; d != nil && d.ErrorExtractor != nil {
+		extractors = append(extractors, d.ErrorExtractor)
+	}
+	return extractErrorInfo(err, append(extractors, DefaultErrorExtractor))
+}
+
+// extractErrorInfo returns the information by the first extractor which supports one of the errors in the tree.
+// If the error is not classified, it's classified as timeout for deadlines and timeouts.
+func extractErrorInfo(err error, extractors []ErrorExtractor) ErrorInfo {
+	var info ErrorInfo
+	walkErrors(err, func(e error) bool {
+		for _, extractor := range extractors {
+			if v, ok := extractor(e); ok {
+				info = v
+				return true
+			}
+		}
+		return false
+	})
+	if info.Class == "" && isTimeout(err) {
+		info.Class = ErrorClassTimeout
+	}
+	return info
+}
+
+// walkErrors calls f for each error in the tree of err in pre-order until f returns true.
+func walkErrors(err error, f func(error) bool) bool {
+	if err == nil {
+		return false
+	}
+	if f(err) {
+		return true
+	}
+	switch v := err.(type) { // nolint:errorlint
+	case interface{ Unwrap() error }:
+		return walkErrors(v.Unwrap(), f)
+	case interface{ Unwrap() []error }:
+		for _, e := range v.Unwrap() {
+			if walkErrors(e, f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var t interface{ Timeout() bool }
+	return errors.As(err, &t) && t.Timeout()
+}
+
+// DefaultErrorExtractor is the extractor for the errors of the well-known drivers.
+// It tries [MySQLErrorExtractor], [SQLiteErrorExtractor] and [PostgresErrorExtractor] in order.
+func DefaultErrorExtractor(err error) (ErrorInfo, bool) {
+	for _, extractor := range []ErrorExtractor{MySQLErrorExtractor, SQLiteErrorExtractor, PostgresErrorExtractor} {
+		if info, ok := extractor(err); ok {
+			return info, true
+		}
+	}
+	return ErrorInfo{}, false
+}
+
+// MySQLErrorExtractor is the extractor for the errors of the structs with Number and SQLState fields
+// like github.com/go-sql-driver/mysql.MySQLError.
+func MySQLErrorExtractor(err error) (ErrorInfo, bool) {
+	v := structValue(err)
+	if !v.IsValid() || intField(v, "Number") == nil {
+		return ErrorInfo{}, false
+	}
+	number := *intField(v, "Number")
+	info := ErrorInfo{SQLState: sqlStateOf(err), Code: strconv.FormatInt(number, 10), Class: classifyMySQLError(number)}
+	if info.SQLState == "" {
+		info.SQLState = stringField(v, "SQLState")
+	}
+	if info.Class == "" {
+		info.Class = classifySQLState(info.SQLState)
+	}
+	return info, true
+}
+
+// SQLiteErrorExtractor is the extractor for the errors of the structs with Code and ExtendedCode fields of integers
+// like github.com/mattn/go-sqlite3.Error.
+func SQLiteErrorExtractor(err error) (ErrorInfo, bool) {
+	v := structValue(err)
+	if !v.IsValid() || intField(v, "Code") == nil || intField(v, "ExtendedCode") == nil {
+		return ErrorInfo{}, false
+	}
+	code, extended := *intField(v, "Code"), *intField(v, "ExtendedCode")
+	info := ErrorInfo{SQLState: sqlStateOf(err), Code: strconv.FormatInt(code, 10), Class: classifySQLiteError(code)}
+	if extended != 0 {
+		info.Code = strconv.FormatInt(extended, 10)
+	}
+	if info.Class == "" {
+		info.Class = classifySQLState(info.SQLState)
+	}
+	return info, true
+}
+
+// PostgresErrorExtractor is the extractor for the errors which have the SQLState() method,
+// and the errors of the structs with Code field of SQLSTATE
+// like github.com/lib/pq.Error and github.com/jackc/pgx/v5/pgconn.PgError.
+func PostgresErrorExtractor(err error) (ErrorInfo, bool) {
+	state := sqlStateOf(err)
+	if v := structValue(err); state == "" && v.IsValid() && isSQLState(stringField(v, "Code")) {
+		state = stringField(v, "Code")
+	}
+	if state == "" {
+		return ErrorInfo{}, false
+	}
+	return ErrorInfo{SQLState: state, Class: classifySQLState(state)}, true
+}
+
+// sqlStateOf returns the result of the SQLState() method of the error, or an empty string.
+func sqlStateOf(err error) string {
+	if v, ok := err.(interface{ SQLState() string }); ok { // nolint:errorlint
+		return v.SQLState()
+	}
+	return ""
+}
+
+// structValue returns the struct value which err points to, or an invalid value.
+func structValue(err error) reflect.Value {
+	v := reflect.ValueOf(err)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return v
+}
+
+// intField returns the value of the integer field, or nil if the struct doesn't have it.
+func intField(v reflect.Value, name string) *int64 {
+	f := v.FieldByName(name)
+	switch f.Kind() { // nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		r := f.Int()
+		return &r
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		r := int64(f.Uint()) // nolint:gosec
+		return &r
+	default:
+		return nil
+	}
+}
+
+// stringField returns the value of the field of a string or a byte array.
+func stringField(v reflect.Value, name string) string {
+	f := v.FieldByName(name)
+	switch {
+	case f.Kind() == reflect.String:
+		return f.String()
+	case f.Kind() == reflect.Array && f.Type().Elem().Kind() == reflect.Uint8:
+		b := make([]byte, f.Len())
+		for i := range b {
+			b[i] = byte(f.Index(i).Uint())
+		}
+		return strings.TrimRight(string(b), "\x00")
+	default:
+		return ""
+	}
+}
+
+// isSQLState returns true if s looks like SQLSTATE which consists of 5 digits or uppercase letters.
+func isSQLState(s string) bool {
+	if len(s) != 5 { // nolint:mnd
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func classifySQLState(state string) ErrorClass {
+	switch {
+	case strings.HasPrefix(state, "23"):
+		return ErrorClassConstraintViolation
+	case state == "40P01":
+		return ErrorClassDeadlock
+	case state == "40001":
+		return ErrorClassSerializationFailure
+	case state == "57014", state == "55P03", state == "HYT00":
+		return ErrorClassTimeout
+	default:
+		return ""
+	}
+}
+
+// https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+func classifyMySQLError(number int64) ErrorClass {
+	switch number {
+	case 1022, 1048, 1062, 1169, 1216, 1217, 1451, 1452, 1557, 1586, 3819, 4025:
+		return ErrorClassConstraintViolation
+	case 1213:
+		return ErrorClassDeadlock
+	case 1205, 3024:
+		return ErrorClassTimeout
+	default:
+		return ""
+	}
+}
+
+// https://www.sqlite.org/rescode.html
+func classifySQLiteError(code int64) ErrorClass {
+	const sqliteConstraint = 19
+	if code&0xff == sqliteConstraint {
+		return ErrorClassConstraintViolation
+	}
+	return ""
+}
@@ -0,0 +1,182 @@
+package sqlslog
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+)
+
+// Errors which have the same shapes as the errors of the drivers.
+
+type mockMySQLError struct {
+	Number   uint16
+	SQLState [5]byte
+	Message  string
+}
+
+func (e *mockMySQLError) Error() string { return e.Message }
+
+type mockPqErrorCode string
+
+type mockPqError struct {
+	Code    mockPqErrorCode
+	Message string
+}
+
+func (e *mockPqError) Error() string { return e.Message }
+
+type mockPgError struct {
+	Code    string
+	Message string
+}
+
+func (e *mockPgError) Error() string    { return e.Message }
+func (e *mockPgError) SQLState() string { return e.Code }
+
+type mockSQLiteError struct {
+	Code         int
+	ExtendedCode int
+}
+
+func (e mockSQLiteError) Error() string { return "sqlite error" }
+
+type mockTimeoutError struct{}
+
+func (mockTimeoutError) Error() string { return "i/o timeout" }
+func (mockTimeoutError) Timeout() bool { return true }
+
+func TestExtractErrorInfo(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected ErrorInfo
+	}{
+		{
+			name:     "mysql duplicate entry",
+			err:      &mockMySQLError{Number: 1062, SQLState: [5]byte{'2', '3', '0', '0', '0'}, Message: "Duplicate entry"},
+			expected: ErrorInfo{SQLState: "23000", Code: "1062", Class: ErrorClassConstraintViolation},
+		},
+		{
+			name:     "mysql deadlock",
+			err:      fmt.Errorf("wrapped: %w", &mockMySQLError{Number: 1213, SQLState: [5]byte{'4', '0', '0', '0', '1'}}),
+			expected: ErrorInfo{SQLState: "40001", Code: "1213", Class: ErrorClassDeadlock},
+		},
+		{
+			name:     "mysql lock wait timeout",
+			err:      &mockMySQLError{Number: 1205, SQLState: [5]byte{'H', 'Y', '0', '0', '0'}},
+			expected: ErrorInfo{SQLState: "HY000", Code: "1205", Class: ErrorClassTimeout},
+		},
+		{
+			name:     "pq unique violation",
+			err:      &mockPqError{Code: "23505"},
+			expected: ErrorInfo{SQLState: "23505", Class: ErrorClassConstraintViolation},
+		},
+		{
+			name:     "pgconn serialization failure",
+			err:      &mockPgError{Code: "40001"},
+			expected: ErrorInfo{SQLState: "40001", Class: ErrorClassSerializationFailure},
+		},
+		{
+			name:     "pgconn deadlock",
+			err:      errors.Join(errors.New("other"), &mockPgError{Code: "40P01"}),
+			expected: ErrorInfo{SQLState: "40P01", Class: ErrorClassDeadlock},
+		},
+		{
+			name:     "pgconn statement timeout",
+			err:      &mockPgError{Code: "57014"},
+			expected: ErrorInfo{SQLState: "57014", Class: ErrorClassTimeout},
+		},
+		{
+			name:     "sqlite primary key",
+			err:      mockSQLiteError{Code: 19, ExtendedCode: 1555},
+			expected: ErrorInfo{Code: "1555", Class: ErrorClassConstraintViolation},
+		},
+		{
+			name:     "sqlite busy",
+			err:      mockSQLiteError{Code: 5},
+			expected: ErrorInfo{Code: "5"},
+		},
+		{
+			name:     "deadline exceeded",
+			err:      fmt.Errorf("query: %w", context.DeadlineExceeded),
+			expected: ErrorInfo{Class: ErrorClassTimeout},
+		},
+		{
+			name:     "net timeout",
+			err:      mockTimeoutError{},
+			expected: ErrorInfo{Class: ErrorClassTimeout},
+		},
+		{
+			name:     "not a database error",
+			err:      &mockPqError{Code: "unknown"},
+			expected: ErrorInfo{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if actual := extractErrorInfo(tc.err, []ErrorExtractor{DefaultErrorExtractor}); actual != tc.expected {
+				t.Errorf("Expected %+v but got %+v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestErrorEnrichment(t *testing.T) {
+	t.Parallel()
+	custom := func(err error) (ErrorInfo, bool) {
+		if err.Error() == "custom" {
+			return ErrorInfo{Code: "C1"}, true
+		}
+		return ErrorInfo{}, false
+	}
+	for _, tc := range []struct {
+		name     string
+		err      error
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "disabled",
+			err:      &mockPgError{Code: "23505", Message: "duplicate key"},
+			expected: "level=ERROR msg=Conn.ExecContext query=\"INSERT INTO users\" args=[] error=\"duplicate key\"\n",
+		},
+		{
+			name:     "enabled",
+			err:      &mockPgError{Code: "23505", Message: "duplicate key"},
+			opts:     []Option{ErrorEnrichment(true)},
+			expected: "level=ERROR msg=Conn.ExecContext query=\"INSERT INTO users\" args=[] error=\"duplicate key\" sqlstate=23505 error_class=constraint_violation\n",
+		},
+		{
+			name:     "custom extractor",
+			err:      errors.New("custom"),
+			opts:     []Option{ErrorEnrichment(true), ErrorExtractors(custom)},
+			expected: "level=ERROR msg=Conn.ExecContext query=\"INSERT INTO users\" args=[] error=custom error_code=C1\n",
+		},
+		{
+			name:     "bad conn",
+			err:      driver.ErrBadConn,
+			opts:     []Option{ErrorEnrichment(true)},
+			expected: "level=WARN msg=Conn.ExecContext query=\"INSERT INTO users\" args=[] error=\"driver: bad connection\" bad_conn=true\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			buf := bytes.NewBuffer(nil)
+			o := newOptions("postgres", tc.opts...)
+			handler := NewTextHandler(buf, &slog.HandlerOptions{ReplaceAttr: removeTimeAndDurationForTest})
+			logger := newStepLogger(slog.New(handler), o.stepLoggerOptions)
+			conn := wrapConn(newMockErrConn(tc.err), logger, o.DriverOptions.ConnOptions)
+			if _, err := conn.(driver.ExecerContext).ExecContext(context.Background(), "INSERT INTO users", nil); err == nil {
+				t.Fatal("Expected an error")
+			}
+			if actual := buf.String(); actual != tc.expected {
+				t.Errorf("Expected %q but got %q", tc.expected, actual)
+			}
+		})
+	}
+}
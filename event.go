@@ -7,6 +7,8 @@ const (
 	EventStart    Event = iota + 1 // Event when the step starts.
 	EventError                     // Event when the step ends with an error.
 	EventComplete                  // Event when the step completes successfully.
+	EventCanceled                  // Event when the step ends with context.Canceled.
+	EventTimeout                   // Event when the step ends with context.DeadlineExceeded or a timeout error.
 )
 
 // String returns the string representation of the event.
@@ -18,6 +20,10 @@ func (pe *Event) String() string {
 		return "Error"
 	case EventComplete:
 		return "Complete"
+	case EventCanceled:
+		return "Canceled"
+	case EventTimeout:
+		return "Timeout"
 	default:
 		return "Unknown"
 	}
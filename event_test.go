@@ -24,6 +24,16 @@ func TestEventString(t *testing.T) {
 			e:    EventComplete,
 			want: "Complete",
 		},
+		{
+			name: "EventCanceled",
+			e:    EventCanceled,
+			want: "Canceled",
+		},
+		{
+			name: "EventTimeout",
+			e:    EventTimeout,
+			want: "Timeout",
+		},
 		{
 			name: "Unknown",
 			e:    Event(0),
@@ -0,0 +1,352 @@
+package sqlslog
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+type explainOptions struct {
+	threshold time.Duration
+	interval  time.Duration
+	opIDKey   string
+	idGen     IDGen
+}
+
+func defaultExplainOptions() explainOptions {
+	return explainOptions{
+		interval: ExplainIntervalDefault,
+		opIDKey:  OpIDKeyDefault,
+		idGen:    IDGeneratorDefault,
+	}
+}
+
+// ExplainSlowQueries sets the threshold of the duration for SELECT queries to be explained.
+// When a SELECT query takes longer than the threshold, sqlslog runs EXPLAIN
+// (EXPLAIN QUERY PLAN for SQLite) for the same query and args on a separate connection
+// from the original connector in background, and logs the plan as an Explain event of the step
+// such as "Conn.QueryContext Explain" and "Stmt.QueryContext Explain".
+// The steps which may be explained have an operation ID to link them with the event. See [OpIDKey] for the key.
+// At most two queries are explained at the same time for a DB because the connections for them
+// are not limited by [sql.DB.SetMaxOpenConns]. The slow queries are not explained while they are running.
+// The running explanations are canceled when the DB is closed.
+// The queries which are not SELECT are never explained.
+// If it's zero or negative, no query is explained.
+// The default is zero.
+func ExplainSlowQueries(threshold time.Duration) Option {
+	return func(o *options) { o.stepLoggerOptions.explain.threshold = threshold }
+}
+
+// ExplainInterval sets the minimum interval to explain the queries with the same fingerprint.
+// The default is ExplainIntervalDefault.
+func ExplainInterval(d time.Duration) Option {
+	return func(o *options) { o.stepLoggerOptions.explain.interval = d }
+}
+
+// OpIDKey sets the key for the operation ID which links a step and its Explain event.
+// The default is OpIDKeyDefault.
+func OpIDKey(key string) Option {
+	return func(o *options) { o.stepLoggerOptions.explain.opIDKey = key }
+}
+
+const (
+	ExplainIntervalDefault = time.Minute
+	OpIDKeyDefault         = "op_id"
+)
+
+const (
+	explainMsgSuffix = " Explain"
+	explainLevel     = LevelInfo
+	explainTimeout   = 30 * time.Second
+	planKey          = "plan"
+	// explainConcurrency is the maximum number of the queries explained at the same time for a DB.
+	explainConcurrency = 2
+)
+
+// explainer is the state to explain the queries shared by the loggers for a DB.
+type explainer struct {
+	mu sync.Mutex
+	// last is the time when the queries are explained by the fingerprints.
+	// The entries older than the interval are evicted at most once in the interval.
+	last   map[string]time.Time
+	pruned time.Time
+	// sem limits the number of the running explanations.
+	sem chan struct{}
+	wg  sync.WaitGroup
+	// ctx is the base context of the explanations which is canceled by close.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newExplainer() *explainer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &explainer{
+		last:   map[string]time.Time{},
+		pruned: time.Now(),
+		sem:    make(chan struct{}, explainConcurrency),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// allow returns true if the query with the fingerprint can be explained now.
+func (e *explainer) allow(fingerprint string, interval time.Duration) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := time.Now()
+	if now.Sub(e.pruned) >= interval {
+		for k, last := range e.last {
+			if now.Sub(last) >= interval {
+				delete(e.last, k)
+			}
+		}
+		e.pruned = now
+	}
+	if last, ok := e.last[fingerprint]; ok && now.Sub(last) < interval {
+		return false
+	}
+	e.last[fingerprint] = now
+	return true
+}
+
+// acquire returns true if a query can be explained without exceeding explainConcurrency.
+// The caller must call release after the explanation if it returns true.
+func (e *explainer) acquire() bool {
+	select {
+	case e.sem <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *explainer) release() {
+	<-e.sem
+}
+
+// run runs f in background with the base context unless the explainer is closed.
+// It releases the slot acquired by the caller when f returns.
+func (e *explainer) run(f func(ctx context.Context)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.ctx.Err() != nil {
+		e.release()
+		return
+	}
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer e.release()
+		f(e.ctx)
+	}()
+}
+
+// wait waits for the running explanations to finish.
+func (e *explainer) wait() {
+	e.wg.Wait()
+}
+
+// close cancels the running explanations and waits for them to finish.
+// No query is explained after close.
+func (e *explainer) close() {
+	e.mu.Lock()
+	e.cancel()
+	e.mu.Unlock()
+	e.wait()
+}
+
+// withConnector returns a stepLogger which explains the queries on a connection from the given connector.
+// The connector must not be wrapped by sqlslog not to log the explanations as steps.
+func (x *stepLogger) withConnector(connector driver.Connector) *stepLogger {
+	r := *x
+	r.connector = connector
+	return &r
+}
+
+// opID returns a new operation ID if the step may be explained, otherwise returns an empty string.
+func (x *stepLogger) opID(step *StepOptions) string {
+	if x.options.explainThreshold() <= 0 || x.connector == nil || x.query == nil || !step.step.runsQuery() ||
+		x.explainPrefix() == "" {
+		return ""
+	}
+	if x.query.Operation() != OperationSelect {
+		return ""
+	}
+	return x.options.explain.idGen()
+}
+
+// explainSlowQuery explains the query in background if the step with the operation ID is slow.
+func (x *stepLogger) explainSlowQuery(step Step, opID string, d time.Duration, err error) {
+	if opID == "" || err != nil || d < x.options.explainThreshold() {
+		return
+	}
+	if !x.explainer.acquire() {
+		return
+	}
+	if !x.explainer.allow(x.query.Fingerprint(), x.options.explain.interval) {
+		x.explainer.release()
+		return
+	}
+	x.explainer.run(func(ctx context.Context) { x.explain(ctx, step, opID) })
+}
+
+func (x *stepLogger) explain(ctx context.Context, step Step, opID string) {
+	ctx, cancel := context.WithTimeout(ctx, explainTimeout)
+	defer cancel()
+	msg := step.String() + explainMsgSuffix
+	attrs := append([]interface{}{
+		slog.String(x.options.explain.opIDKey, opID),
+		slog.String(x.options.queryOptions.fingerprintKey, x.query.Fingerprint()),
+	}, x.queryAttrs()...)
+	plan, err := explainQuery(ctx, x.connector, x.explainPrefix()+x.query.text, namedValues(x.args))
+	if x.explainer.ctx.Err() != nil {
+		// The DB is closed.
+		return
+	}
+	if err != nil {
+		x.Log(ctx, slog.Level(LevelWarn), msg, append(attrs, slog.Any("error", err))...)
+		return
+	}
+	x.Log(ctx, slog.Level(explainLevel), msg, append(attrs, slog.Any(planKey, plan))...)
+}
+
+// explainPrefix returns the prefix to explain the query in the dialect.
+func (x *stepLogger) explainPrefix() string {
+	if x.options.dialect == nil {
+		return DialectGeneric.ExplainPrefix
+	}
+	return x.options.dialect.ExplainPrefix
+}
+
+// explainQuery runs the query on a new connection from the connector and returns the rows as lines.
+func explainQuery(ctx context.Context, connector driver.Connector, query string, args []driver.NamedValue) ([]string, error) {
+	conn, err := connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := queryConn(ctx, conn, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dest := make([]driver.Value, len(rows.Columns()))
+	var r []string
+	for {
+		if err := rows.Next(dest); err != nil {
+			if errors.Is(err, io.EOF) {
+				return r, nil
+			}
+			return nil, err
+		}
+		cols := make([]string, len(dest))
+		for i, v := range dest {
+			cols[i] = planValueString(v)
+		}
+		r = append(r, strings.Join(cols, " | "))
+	}
+}
+
+// queryConn runs the query by the interfaces which the connection implements.
+func queryConn(ctx context.Context, conn driver.Conn, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := conn.(driver.QueryerContext); ok {
+		rows, err := queryer.QueryContext(ctx, query, args)
+		if !errors.Is(err, driver.ErrSkip) {
+			return rows, err
+		}
+	}
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rows driver.Rows
+	if sq, ok := stmt.(driver.StmtQueryContext); ok {
+		rows, err = sq.QueryContext(ctx, args)
+	} else {
+		values := make([]driver.Value, len(args))
+		for i, arg := range args {
+			values[i] = arg.Value
+		}
+		rows, err = stmt.Query(values) //nolint:staticcheck
+	}
+	if err != nil {
+		_ = stmt.Close()
+		return nil, err
+	}
+	return &stmtRows{Rows: rows, stmt: stmt}, nil
+}
+
+// stmtRows closes the statement when the rows are closed.
+type stmtRows struct {
+	driver.Rows
+	stmt driver.Stmt
+}
+
+func (r *stmtRows) Close() error {
+	return errors.Join(r.Rows.Close(), r.stmt.Close())
+}
+
+// namedValues returns the args as []driver.NamedValue.
+func namedValues(args interface{}) []driver.NamedValue {
+	switch v := args.(type) {
+	case []driver.NamedValue:
+		return v
+	case []driver.Value:
+		r := make([]driver.NamedValue, len(v))
+		for i, value := range v {
+			r[i] = driver.NamedValue{Ordinal: i + 1, Value: value}
+		}
+		return r
+	default:
+		return nil
+	}
+}
+
+func planValueString(v driver.Value) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// unwrapConnector returns the connector which is not wrapped by sqlslog.
+func unwrapConnector(c driver.Connector) driver.Connector {
+	switch v := c.(type) {
+	case *connector:
+		return unwrapConnector(v.original)
+	case *dsnConnector:
+		return &dsnConnector{dsn: v.dsn, driver: unwrapDriver(v.driver)}
+	default:
+		return c
+	}
+}
+
+// unwrapDriver returns the driver which is not wrapped by sqlslog.
+func unwrapDriver(d driver.Driver) driver.Driver {
+	switch v := d.(type) {
+	case *driverWrapper:
+		return v.original
+	case *driverContextWrapper:
+		return v.driverWrapper.original
+	default:
+		return d
+	}
+}
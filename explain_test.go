@@ -0,0 +1,242 @@
+package sqlslog
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockExplainConnector returns connections which record the EXPLAIN queries.
+type mockExplainConnector struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (m *mockExplainConnector) Connect(context.Context) (driver.Conn, error) {
+	return &mockExplainConn{mockResultConn: mockResultConn{rows: 1}, connector: m}, nil
+}
+
+func (m *mockExplainConnector) Driver() driver.Driver { return nil }
+
+type mockExplainConn struct {
+	mockResultConn
+	connector *mockExplainConnector
+}
+
+func (m *mockExplainConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if strings.HasPrefix(query, "EXPLAIN") {
+		m.connector.mu.Lock()
+		m.connector.queries = append(m.connector.queries, query)
+		m.connector.mu.Unlock()
+	}
+	return m.mockResultConn.QueryContext(ctx, query, args)
+}
+
+// blockingConnector blocks Connect until the context is done.
+type blockingConnector struct {
+	connecting chan struct{}
+}
+
+func (m *blockingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	close(m.connecting)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (m *blockingConnector) Driver() driver.Driver { return nil }
+
+// syncBuffer is a bytes.Buffer which can be written by multiple goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestExplainSlowQueries(t *testing.T) {
+	t.Parallel()
+
+	setup := func(opts ...Option) (*syncBuffer, *stepLogger, *mockExplainConnector, driver.Conn) {
+		buf := &syncBuffer{}
+		o := newOptions("sqlite3", opts...)
+		logger := newStepLogger(slog.New(NewTextHandler(buf, &slog.HandlerOptions{ReplaceAttr: removeTimeAndDurationForTest})), o.stepLoggerOptions)
+		explainConnector := &mockExplainConnector{}
+		conn, err := wrapConnector(explainConnector, logger, o.DriverOptions.ConnectorOptions).Connect(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return buf, logger, explainConnector, conn
+	}
+
+	t.Run("slow select", func(t *testing.T) {
+		t.Parallel()
+		buf, logger, explainConnector, conn := setup(ExplainSlowQueries(time.Nanosecond), IDGenerator(func() string { return "op1" }))
+		queryer := conn.(driver.QueryerContext)
+		for range 2 {
+			if _, err := queryer.QueryContext(context.Background(), "SELECT * FROM users WHERE id = ?", []driver.NamedValue{{Ordinal: 1, Value: 1}}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := queryer.QueryContext(context.Background(), "DELETE FROM users RETURNING id", nil); err != nil {
+			t.Fatal(err)
+		}
+		logger.explainer.wait()
+
+		if len(explainConnector.queries) != 1 || explainConnector.queries[0] != "EXPLAIN QUERY PLAN SELECT * FROM users WHERE id = ?" {
+			t.Fatalf("Unexpected queries: %q", explainConnector.queries)
+		}
+		out := buf.String()
+		if c := strings.Count(out, `msg=Conn.QueryContext query="SELECT * FROM users WHERE id = ?" args="[{Name: Ordinal:1 Value:1}]" op_id=op1`); c != 2 {
+			t.Errorf("Expected 2 steps with op_id, got %d in %q", c, out)
+		}
+		if strings.Contains(out, `query="DELETE FROM users RETURNING id" args=[] op_id=`) {
+			t.Errorf("Unexpected op_id for DELETE in %q", out)
+		}
+		expected := regexp.MustCompile(`level=INFO msg="Conn.QueryContext Explain" query="SELECT \* FROM users WHERE id = \?" args="\[{Name: Ordinal:1 Value:1}\]" op_id=op1 query_fingerprint=[0-9a-f]{16} plan=\[0\]`)
+		if !expected.MatchString(out) {
+			t.Errorf("Expected Explain event in %q", out)
+		}
+	})
+
+	t.Run("slow stmt", func(t *testing.T) {
+		t.Parallel()
+		buf, logger, _, conn := setup(ExplainSlowQueries(time.Nanosecond), IDGenerator(func() string { return "op1" }))
+		stmt, err := conn.(driver.ConnPrepareContext).PrepareContext(context.Background(), "SELECT * FROM users")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer stmt.Close()
+		if _, err := stmt.Query(nil); err != nil { //nolint:staticcheck
+			t.Fatal(err)
+		}
+		logger.explainer.wait()
+		if out := buf.String(); !strings.Contains(out, `level=INFO msg="Stmt.Query Explain" stmt_id=op1 args=[] op_id=op1 query_fingerprint=`) {
+			t.Errorf("Expected Explain event of Stmt.Query in %q", out)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		t.Parallel()
+		buf := &syncBuffer{}
+		o := newOptions("sqlite3", ExplainSlowQueries(time.Nanosecond))
+		logger := newStepLogger(slog.New(NewTextHandler(buf, nil)), o.stepLoggerOptions)
+		blocking := &blockingConnector{connecting: make(chan struct{})}
+		wrapped := wrapConnector(blocking, logger, o.DriverOptions.ConnectorOptions)
+		conn := wrapConn(&mockResultConn{rows: 1}, logger.withConnector(blocking), o.DriverOptions.ConnOptions)
+		if _, err := conn.(driver.QueryerContext).QueryContext(context.Background(), "SELECT 1", nil); err != nil {
+			t.Fatal(err)
+		}
+		<-blocking.connecting
+		if err := wrapped.(io.Closer).Close(); err != nil {
+			t.Fatal(err)
+		}
+		if out := buf.String(); strings.Contains(out, "Explain") {
+			t.Errorf("Unexpected Explain event after close in %q", out)
+		}
+		if _, err := conn.(driver.QueryerContext).QueryContext(context.Background(), "SELECT id FROM users", nil); err != nil {
+			t.Fatal(err)
+		}
+		logger.explainer.wait()
+		if out := buf.String(); strings.Contains(out, "Explain") {
+			t.Errorf("Unexpected Explain event after close in %q", out)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		buf, logger, explainConnector, conn := setup()
+		if _, err := conn.(driver.QueryerContext).QueryContext(context.Background(), "SELECT 1", nil); err != nil {
+			t.Fatal(err)
+		}
+		logger.explainer.wait()
+		if len(explainConnector.queries) != 0 || strings.Contains(buf.String(), "op_id") {
+			t.Fatalf("Unexpected explain: %q %q", explainConnector.queries, buf.String())
+		}
+	})
+}
+
+func TestExplainer(t *testing.T) {
+	t.Parallel()
+	e := newExplainer()
+	if !e.allow("a", time.Hour) {
+		t.Error("Expected to allow a")
+	}
+	if e.allow("a", time.Hour) {
+		t.Error("Expected not to allow a again")
+	}
+	if !e.allow("b", time.Hour) {
+		t.Error("Expected to allow b")
+	}
+	if !e.allow("a", 0) {
+		t.Error("Expected to allow a without interval")
+	}
+
+	t.Run("prune", func(t *testing.T) {
+		t.Parallel()
+		e := newExplainer()
+		e.allow("a", time.Hour)
+		e.allow("b", time.Hour)
+		e.last["a"] = time.Now().Add(-2 * time.Hour)
+		e.pruned = time.Now().Add(-2 * time.Hour)
+		e.allow("c", time.Hour)
+		if _, ok := e.last["a"]; ok {
+			t.Error("Expected a to be evicted")
+		}
+		if len(e.last) != 2 {
+			t.Errorf("Expected b and c to be kept but got %v", e.last)
+		}
+	})
+
+	t.Run("concurrency", func(t *testing.T) {
+		t.Parallel()
+		e := newExplainer()
+		for i := 0; i < explainConcurrency; i++ {
+			if !e.acquire() {
+				t.Fatalf("Expected to acquire %d", i)
+			}
+		}
+		if e.acquire() {
+			t.Error("Expected not to acquire over the limit")
+		}
+		e.release()
+		if !e.acquire() {
+			t.Error("Expected to acquire after release")
+		}
+	})
+}
+
+func TestExplainPrefix(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		dialect  *Dialect
+		expected string
+	}{
+		{nil, "EXPLAIN "},
+		{DialectPostgres, "EXPLAIN "},
+		{DialectMySQL, "EXPLAIN "},
+		{DialectSQLite3, "EXPLAIN QUERY PLAN "},
+		{DialectSQLServer, ""},
+	} {
+		x := &stepLogger{options: &stepLoggerOptions{dialect: tc.dialect}}
+		if actual := x.explainPrefix(); actual != tc.expected {
+			t.Errorf("%v: expected %q but got %q", tc.dialect, tc.expected, actual)
+		}
+	}
+}
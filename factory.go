@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"log/slog"
+	"sync"
 )
 
 type Factory struct {
@@ -12,6 +13,11 @@ type Factory struct {
 	driverName string
 	dsn        string
 
+	// opts is the options given by New to rebuild the options by Reset.
+	opts []Option
+	// mu guards options which are replaced by Update and Reset.
+	mu sync.Mutex
+
 	handler slog.Handler
 	logger  *slog.Logger
 }
@@ -22,6 +28,7 @@ func New(driverName, dsn string, opts ...Option) *Factory {
 		driverName: driverName,
 		dsn:        dsn,
 		options:    options,
+		opts:       append([]Option{}, opts...),
 		handler:    options.SlogOptions.handler,
 	}
 }
@@ -46,11 +53,31 @@ func (f *Factory) Logger() *slog.Logger {
 }
 
 func (f *Factory) Open(ctx context.Context) (*sql.DB, error) {
-	stepLogger := newStepLogger(f.Logger(), f.options.stepLoggerOptions)
-	return open(ctx, f.driverName, f.dsn, stepLogger, f.options)
+	// The options are locked because they are replaced by the dialect detected from the driver.
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// This db is not used directly, but it is used to get the driver.
+	// sql.Open doesn't connect to the database.
+	base, openErr := sql.Open(f.driverName, f.dsn)
+	var drv driver.Driver
+	if openErr == nil {
+		drv = base.Driver()
+		f.detectDialect(drv)
+	}
+	stepLogger := newStepLogger(f.stepSlogLogger(), f.options.stepLoggerOptions)
+	return open(ctx, f.driverName, f.dsn, drv, openErr, stepLogger, f.options)
 }
 
-func open(ctx context.Context, driverName, dsn string, logger *stepLogger, options *options) (*sql.DB, error) {
+// open opens a database with the driver returned by sql.Open, or logs openErr returned by it.
+func open(ctx context.Context, driverName, dsn string, drv driver.Driver, openErr error, logger *stepLogger, options *options) (*sql.DB, error) {
+	if attrs := options.dbAttrs(dsn); len(attrs) > 0 {
+		args := make([]interface{}, len(attrs))
+		for i, attr := range attrs {
+			args[i] = attr
+		}
+		logger = logger.With(args...)
+	}
+
 	lg := logger.With(
 		slog.String("driver", driverName),
 		slog.String("dsn", dsn),
@@ -58,8 +85,11 @@ func open(ctx context.Context, driverName, dsn string, logger *stepLogger, optio
 
 	var db *sql.DB
 	err := ignoreAttr(lg.Step(ctx, &options.Open, func() (*slog.Attr, error) {
+		if openErr != nil {
+			return nil, openErr
+		}
 		var err error
-		db, err = openWithDriver(driverName, dsn, logger, options.DriverOptions)
+		db, err = openWithDriver(drv, dsn, logger, options.DriverOptions)
 		return nil, err
 	}))
 	if err != nil {
@@ -68,15 +98,8 @@ func open(ctx context.Context, driverName, dsn string, logger *stepLogger, optio
 	return db, nil
 }
 
-func openWithDriver(driverName, dsn string, logger *stepLogger, driverOptions *driverOptions) (*sql.DB, error) {
-	db, err := sql.Open(driverName, dsn)
-	if err != nil {
-		return nil, err
-	}
-	// This db is not used directly, but it is used to get the driver.
-
-	drv := wrapDriver(db.Driver(), logger, driverOptions)
-
+func openWithDriver(original driver.Driver, dsn string, logger *stepLogger, driverOptions *driverOptions) (*sql.DB, error) {
+	drv := wrapDriver(original, logger, driverOptions)
 	return openWithWrappedDriver(drv, dsn, logger, driverOptions)
 }
 
@@ -93,5 +116,10 @@ func openWithWrappedDriver(drv driver.Driver, dsn string, logger *stepLogger, dr
 		origConnector = &dsnConnector{dsn: dsn, driver: drv}
 	}
 
-	return sql.OpenDB(wrapConnector(origConnector, logger, driverOptions.ConnectorOptions)), nil
+	wrapped := wrapConnector(origConnector, logger, driverOptions.ConnectorOptions)
+	db := sql.OpenDB(wrapped)
+	if logger.options.dbStatsInterval > 0 {
+		go logger.reportDBStats(db, wrapped.(*connector).closed)
+	}
+	return db, nil
 }
@@ -11,6 +11,7 @@ func IDGenerator(idGen IDGen) Option {
 	return func(o *options) {
 		o.DriverOptions.IDGen = idGen
 		o.DriverOptions.ConnOptions.IDGen = idGen
+		o.stepLoggerOptions.explain.idGen = idGen
 	}
 }
 
@@ -0,0 +1,131 @@
+package sqlslog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sync"
+	"time"
+)
+
+type leakOptions struct {
+	enabled bool
+	age     time.Duration
+}
+
+func defaultLeakOptions() leakOptions {
+	return leakOptions{age: LeakAgeDefault}
+}
+
+// DetectLeaks sets whether sqlslog tracks Rows, Stmts and Txs to detect leaks.
+// sqlslog logs a "Leak suspected" event at [LevelWarn] with the creating call site and the query
+// when one of them is garbage-collected without being closed, committed or rolled back,
+// or when Rows or a Tx stays open longer than the age set by [LeakAge].
+// The default is false.
+func DetectLeaks(v bool) Option {
+	return func(o *options) { o.stepLoggerOptions.leak.enabled = v }
+}
+
+// LeakAge sets the age of Rows and Txs to be reported as leaks while they are open.
+// Stmts are not reported by their age because they are often prepared once and reused for a long time.
+// If it's zero or negative, they are reported only when they are garbage-collected.
+// The default is LeakAgeDefault.
+func LeakAge(d time.Duration) Option {
+	return func(o *options) { o.stepLoggerOptions.leak.age = d }
+}
+
+// LeakAgeDefault is the default age for [LeakAge].
+const LeakAgeDefault = time.Minute
+
+const (
+	leakMsg   = "Leak suspected"
+	leakLevel = LevelWarn
+
+	leakObjectKey = "object"
+	leakReasonKey = "reason"
+	leakAgeKey    = "age"
+
+	leakObjectRows = "Rows"
+	leakObjectStmt = "Stmt"
+	leakObjectTx   = "Tx"
+
+	leakReasonAge = "open too long"
+	leakReasonGC  = "garbage collected"
+)
+
+// leakRecord is the record of an object which must be closed.
+// It must not refer to the object itself so that the object can be garbage-collected.
+type leakRecord struct {
+	logger  *stepLogger
+	object  string
+	created time.Time
+	callers []uintptr
+
+	mu     sync.Mutex
+	closed bool
+	timer  *time.Timer
+}
+
+// trackLeak starts tracking the object created now.
+// It returns nil if leak detection is disabled.
+// The finalizer is set to obj, which must be the pointer returned by the wrap function.
+func (x *stepLogger) trackLeak(obj interface{}, object string) *leakRecord {
+	if x.options == nil || !x.options.leak.enabled {
+		return nil
+	}
+	pcs := make([]uintptr, callersMaxDepth)
+	n := runtime.Callers(2, pcs) // nolint:mnd
+	r := &leakRecord{logger: x, object: object, created: time.Now(), callers: pcs[:n]}
+	// Stmts are checked only by the finalizer because long-lived ones are usually intended.
+	if age := x.options.leak.age; age > 0 && object != leakObjectStmt {
+		r.mu.Lock()
+		r.timer = time.AfterFunc(age, func() { r.report(leakReasonAge) })
+		r.mu.Unlock()
+	}
+	runtime.SetFinalizer(obj, func(interface{}) { r.report(leakReasonGC) })
+	return r
+}
+
+// close marks the object as closed. It's safe to call close with nil.
+func (r *leakRecord) close() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+}
+
+// report logs a leak event if the object is not closed.
+// It logs only once for the object.
+func (r *leakRecord) report(reason string) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	r.closed = true
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	r.mu.Unlock()
+
+	x := r.logger
+	ctx := x.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	attrs := []interface{}{
+		slog.String(leakObjectKey, r.object),
+		slog.String(leakReasonKey, reason),
+		x.options.durationAttrWithKey(leakAgeKey, time.Since(r.created)),
+		slog.String(callSiteKey, callSiteOf(r.callers)),
+	}
+	if x.query != nil {
+		attrs = append(attrs, x.queryAttrs()...)
+	}
+	x.Log(ctx, slog.Level(leakLevel), leakMsg, attrs...)
+}
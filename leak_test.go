@@ -0,0 +1,109 @@
+package sqlslog
+
+import (
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func waitForLog(t *testing.T, buf *syncBuffer, s string) string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if out := buf.String(); strings.Contains(out, s) {
+			return out
+		}
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Expected %q in %q", s, buf.String())
+	return ""
+}
+
+func TestDetectLeaks(t *testing.T) {
+	t.Parallel()
+
+	newLogger := func(opts ...Option) (*syncBuffer, *stepLogger, *options) {
+		buf := &syncBuffer{}
+		o := newOptions("sqlite3", opts...)
+		handler := NewTextHandler(buf, &slog.HandlerOptions{Level: LevelWarn, ReplaceAttr: removeTimeAndDurationForTest})
+		return buf, newStepLogger(slog.New(handler), o.stepLoggerOptions), o
+	}
+
+	t.Run("open too long", func(t *testing.T) {
+		t.Parallel()
+		buf, logger, o := newLogger(DetectLeaks(true), LeakAge(10*time.Millisecond))
+		conn := wrapConn(&mockResultConn{rows: 1}, logger, o.DriverOptions.ConnOptions)
+		queryer := conn.(driver.QueryerContext)
+
+		stmt, err := conn.(driver.ConnPrepareContext).PrepareContext(context.Background(), "SELECT id FROM posts")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer stmt.Close()
+
+		closed, err := queryer.QueryContext(context.Background(), "SELECT id FROM users", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := closed.Close(); err != nil {
+			t.Fatal(err)
+		}
+		leaked, err := queryer.QueryContext(context.Background(), "SELECT id FROM todos", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer leaked.Close()
+
+		out := waitForLog(t, buf, leakMsg)
+		for _, s := range []string{
+			`level=WARN msg="Leak suspected" object=Rows reason="open too long" age=`,
+			"leak_test.go",
+			`query="SELECT id FROM todos"`,
+		} {
+			if !strings.Contains(out, s) {
+				t.Errorf("Expected %q in %q", s, out)
+			}
+		}
+		if strings.Contains(out, "users") {
+			t.Errorf("Unexpected leak of closed rows in %q", out)
+		}
+		if strings.Contains(out, "object=Stmt") {
+			t.Errorf("Unexpected leak of an open stmt by its age in %q", out)
+		}
+	})
+
+	t.Run("garbage collected", func(t *testing.T) {
+		t.Parallel()
+		buf, logger, o := newLogger(DetectLeaks(true), LeakAge(0))
+		func() {
+			_ = wrapTx(&mockTx{}, logger.With(slog.String("tx_id", "tx1")), o.DriverOptions.ConnOptions.TxOptions)
+		}()
+		out := waitForLog(t, buf, leakMsg)
+		if !strings.Contains(out, `level=WARN msg="Leak suspected" tx_id=tx1 object=Tx reason="garbage collected" age=`) {
+			t.Errorf("Unexpected log: %q", out)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		buf, logger, o := newLogger(LeakAge(time.Nanosecond))
+		tx := wrapTx(&mockTx{}, logger, o.DriverOptions.ConnOptions.TxOptions)
+		if tx.leak != nil {
+			t.Fatal("Expected no leak record")
+		}
+		time.Sleep(10 * time.Millisecond)
+		if buf.String() != "" {
+			t.Errorf("Unexpected log: %q", buf.String())
+		}
+	})
+}
+
+type mockTx struct{}
+
+func (*mockTx) Commit() error   { return nil }
+func (*mockTx) Rollback() error { return nil }
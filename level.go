@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"strings"
 )
 
@@ -70,6 +71,31 @@ func ParseLevel(s string) (Level, error) {
 	return lv, nil
 }
 
+// MarshalText implements encoding.TextMarshaler by String.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// It accepts the names accepted by ParseLevel with an optional offset such as "DEBUG-2" returned by String.
+func (l *Level) UnmarshalText(data []byte) error {
+	s := string(data)
+	name, offset := s, 0
+	if i := strings.IndexAny(s, "+-"); i > 0 {
+		n, err := strconv.Atoi(s[i:])
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrUnknownLevel, s)
+		}
+		name, offset = s[:i], n
+	}
+	lv, err := ParseLevel(name)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrUnknownLevel, s)
+	}
+	*l = lv + Level(offset)
+	return nil
+}
+
 func ParseLevelWithDefault(s string, def Level) Level {
 	lv, err := ParseLevel(s)
 	if err != nil {
@@ -125,3 +125,23 @@ func TestParseLevelWithDefault(t *testing.T) {
 		}
 	})
 }
+
+func TestLevelText(t *testing.T) {
+	t.Parallel()
+	for _, lv := range []Level{LevelVerbose, LevelTrace + 1, LevelDebug - 1, LevelInfo, LevelWarn + 2, LevelError + 4} {
+		b, err := lv.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual Level
+		if err := actual.UnmarshalText(b); err != nil || actual != lv {
+			t.Errorf("%s: expected %v but got %v %v", b, lv, actual, err)
+		}
+	}
+	for _, s := range []string{"", "LOUD", "INFO+", "INFO+x", "+1"} {
+		var actual Level
+		if err := actual.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
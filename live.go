@@ -0,0 +1,205 @@
+package sqlslog
+
+import (
+	"encoding/json"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// liveStepOptions holds the step options which replace the original ones at runtime by [Factory.Update].
+type liveStepOptions struct {
+	atomic.Pointer[StepOptions]
+}
+
+// current returns the options updated at runtime, or the options itself if they're not updated.
+func (o *StepOptions) current() *StepOptions {
+	if o.live != nil {
+		if r := o.live.Load(); r != nil {
+			return r
+		}
+	}
+	return o
+}
+
+// liveOptions holds the options of the step loggers which can be changed at runtime by [Factory.Update].
+// It's shared by all the step loggers of the databases opened by the same factory.
+type liveOptions struct {
+	explainThreshold atomic.Int64
+	sampleRate       atomic.Uint64 // math.Float64bits of the rate
+}
+
+func (l *liveOptions) store(o *stepLoggerOptions) {
+	l.explainThreshold.Store(int64(o.explain.threshold))
+	l.sampleRate.Store(math.Float64bits(o.sampleRate))
+}
+
+// explainThreshold returns the threshold of ExplainSlowQueries which may be updated at runtime.
+func (o *stepLoggerOptions) explainThreshold() time.Duration {
+	if o.live == nil {
+		return o.explain.threshold
+	}
+	return time.Duration(o.live.explainThreshold.Load())
+}
+
+// sampled returns true if the Start and Complete events of a step should be logged by the rate of Sampling.
+func (o *stepLoggerOptions) sampled() bool {
+	rate := o.sampleRate
+	if o.live != nil {
+		rate = math.Float64frombits(o.live.sampleRate.Load())
+	}
+	return rate >= 1 || rand.Float64() < rate // nolint:gosec
+}
+
+// SampleRateDefault is the default rate of [Sampling].
+const SampleRateDefault = 1.0
+
+// Sampling sets the rate of the steps whose Start and Complete events are logged, from 0 to 1.
+// The steps are sampled at random and the failed steps are always logged.
+// The default is SampleRateDefault which logs all the steps.
+func Sampling(rate float64) Option {
+	return func(o *options) { o.stepLoggerOptions.sampleRate = rate }
+}
+
+// clone returns a copy of the options whose step options can be changed without affecting o.
+func (o *options) clone() *options {
+	r := *o
+	d := *o.DriverOptions
+	connector := *d.ConnectorOptions
+	conn := *d.ConnOptions
+	tx := *conn.TxOptions
+	stmt := *conn.StmtOptions
+	rows := *conn.RowsOptions
+	stmt.Rows = &rows
+	conn.TxOptions, conn.StmtOptions, conn.RowsOptions = &tx, &stmt, &rows
+	connector.ConnOptions = &conn
+	d.ConnOptions, d.ConnectorOptions = &conn, &connector
+	r.DriverOptions = &d
+	slogOptions := *o.SlogOptions
+	slogOptions.attrKeys = maps.Clone(o.SlogOptions.attrKeys)
+	r.SlogOptions = &slogOptions
+	r.attrs = slices.Clone(o.attrs)
+	r.stepLoggerOptions.errorExtractors = slices.Clone(o.stepLoggerOptions.errorExtractors)
+	// The maps of the steps are read by the databases without lock, so they're replaced instead of modified.
+	for _, s := range r.stepOptions() {
+		s.OperationLevels = maps.Clone(s.OperationLevels)
+	}
+	return &r
+}
+
+// publish makes the options of updated take effect at runtime and hands over the live options of o
+// to updated, so updated can replace o. updated must have the steps of o in the same order.
+func (o *options) publish(updated *options) {
+	next := updated.stepOptions()
+	for i, s := range o.stepOptions() {
+		next[i].live = s.live
+		if s.live == nil {
+			continue
+		}
+		v := *next[i]
+		v.live = nil
+		s.live.Store(&v)
+	}
+	updated.stepLoggerOptions.live = o.stepLoggerOptions.live
+	o.stepLoggerOptions.live.store(&updated.stepLoggerOptions)
+}
+
+// Update applies the options to the current options of the factory, and makes them take effect
+// for the databases opened by the factory without reopening them.
+// The messages, the levels and the error handlers of the steps, the threshold of [ExplainSlowQueries]
+// and the rate of [Sampling] take effect at runtime. The other options take effect for the databases opened later.
+func (f *Factory) Update(opts ...Option) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	updated := f.options.clone()
+	for _, opt := range opts {
+		opt(updated)
+	}
+	f.options.publish(updated)
+	f.options = updated
+}
+
+// Reset rebuilds the options from the options given by [New] and opts, discarding the previous updates,
+// and makes them take effect like [Factory.Update].
+func (f *Factory) Reset(opts ...Option) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	updated := newOptions(f.driverName, append(append([]Option{}, f.opts...), opts...)...)
+	// Keep the dialect detected from the driver when the databases were opened.
+	if !updated.dialectGiven && updated.dialect != f.options.dialect {
+		updated.setDialect(f.options.dialect)
+		updated.fillDialectErrorHandlers()
+	}
+	f.options.publish(updated)
+	f.options = updated
+}
+
+// Config returns the configuration which is in effect at runtime.
+// It contains the messages and the levels of all the steps, the threshold of [ExplainSlowQueries]
+// and the rate of [Sampling].
+func (f *Factory) Config() *Config {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	o := &f.options.stepLoggerOptions
+	rate := math.Float64frombits(o.live.sampleRate.Load())
+	threshold := ConfigDuration(o.explainThreshold())
+	r := &Config{
+		ExplainSlowQueries: &threshold,
+		SampleRate:         &rate,
+		Steps:              map[Step]*StepConfig{},
+	}
+	event := func(e EventOptions) *EventConfig {
+		return &EventConfig{Msg: e.Msg, Level: &e.Level}
+	}
+	for _, s := range f.options.stepOptions() {
+		cur := s.current()
+		r.Steps[cur.step] = &StepConfig{
+			Start:    event(cur.Start),
+			Error:    event(cur.Error),
+			Complete: event(cur.Complete),
+			Canceled: event(cur.Canceled),
+			Timeout:  event(cur.Timeout),
+		}
+	}
+	return r
+}
+
+// configHandlerMaxBytes is the maximum size of the request body of ConfigHandler.
+const configHandlerMaxBytes = 1 << 20
+
+// ConfigHandler returns the handler to read and modify the configuration at runtime in JSON.
+// GET responds [Factory.Config]. PUT, PATCH and POST load the request body by [LoadConfig]
+// and respond the updated configuration. PUT calls [Factory.Reset] with [Config.Options] to replace
+// the previous updates, and PATCH and POST call [Factory.Update] to modify the current configuration.
+// The request body larger than 1MiB is rejected.
+// The handler doesn't authenticate the requests, so it should be served only to the operators.
+func (f *Factory) ConfigHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPatch, http.MethodPost:
+			c, err := LoadConfig(http.MaxBytesReader(w, r.Body, configHandlerMaxBytes))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if r.Method == http.MethodPut {
+				f.Reset(c.Options()...)
+			} else {
+				f.Update(c.Options()...)
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, PATCH, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(f.Config())
+	})
+}
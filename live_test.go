@@ -0,0 +1,225 @@
+package sqlslog
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newFactoryForLiveTest(buf *bytes.Buffer, opts ...Option) (*Factory, driver.Conn) {
+	f := New("sqlite3", "", append([]Option{
+		LogWriter(buf),
+		HandlerOptions(&slog.HandlerOptions{ReplaceAttr: removeTimeAndDurationForTest}),
+	}, opts...)...)
+	logger := newStepLogger(f.Logger(), f.options.stepLoggerOptions)
+	return f, wrapConn(&mockResultConn{}, logger, f.options.DriverOptions.ConnOptions)
+}
+
+func TestFactoryUpdate(t *testing.T) {
+	t.Parallel()
+
+	exec := func(t *testing.T, conn driver.Conn) {
+		t.Helper()
+		if _, err := conn.(driver.ExecerContext).ExecContext(context.Background(), "DELETE FROM users", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("levels", func(t *testing.T) {
+		t.Parallel()
+		buf := bytes.NewBuffer(nil)
+		f, conn := newFactoryForLiveTest(buf)
+		exec(t, conn)
+		f.Update(ConnExecContext(func(o *StepOptions) { o.SetLevel(LevelDebug) }))
+		exec(t, conn)
+		// The Start event is logged at INFO by SetLevel(LevelWarn).
+		f.Update(ConnExecContext(func(o *StepOptions) { o.Complete.Msg = "Exec" }), ConnExecContext(func(o *StepOptions) { o.SetLevel(LevelWarn) }))
+		exec(t, conn)
+		expected := "level=INFO msg=Conn.ExecContext query=\"DELETE FROM users\" args=[]\n" +
+			"level=INFO msg=Conn.ExecContext query=\"DELETE FROM users\" args=[]\n" +
+			"level=WARN msg=Exec query=\"DELETE FROM users\" args=[]\n"
+		if actual := buf.String(); actual != expected {
+			t.Errorf("Expected %q but got %q", expected, actual)
+		}
+	})
+
+	t.Run("sampling", func(t *testing.T) {
+		t.Parallel()
+		buf := bytes.NewBuffer(nil)
+		f, conn := newFactoryForLiveTest(buf)
+		f.Update(Sampling(0))
+		exec(t, conn)
+		if actual := buf.String(); actual != "" {
+			t.Errorf("Expected no log but got %q", actual)
+		}
+		c := wrapConn(newMockErrConn(errors.New("unexpected")), newStepLogger(f.Logger(), f.options.stepLoggerOptions), f.options.DriverOptions.ConnOptions)
+		_, _ = c.(driver.ExecerContext).ExecContext(context.Background(), "DELETE FROM users", nil)
+		expected := "level=ERROR msg=Conn.ExecContext query=\"DELETE FROM users\" args=[] error=unexpected\n"
+		if actual := buf.String(); actual != expected {
+			t.Errorf("Expected %q but got %q", expected, actual)
+		}
+		f.Update(Sampling(1))
+		buf.Reset()
+		exec(t, conn)
+		if actual := buf.String(); actual == "" {
+			t.Error("Expected log")
+		}
+	})
+
+	t.Run("explain threshold", func(t *testing.T) {
+		t.Parallel()
+		f := New("sqlite3", "", ExplainSlowQueries(time.Second))
+		original := f.options
+		f.Update(ExplainSlowQueries(time.Millisecond))
+		if actual := original.explainThreshold(); actual != time.Millisecond {
+			t.Errorf("Unexpected threshold: %v", actual)
+		}
+		if actual := original.explain.threshold; actual != time.Second {
+			t.Errorf("Expected the original options not to be modified but got %v", actual)
+		}
+		if actual := f.options.explain.threshold; actual != time.Millisecond {
+			t.Errorf("Expected the current options to be updated but got %v", actual)
+		}
+	})
+
+	t.Run("snapshot", func(t *testing.T) {
+		t.Parallel()
+		f := New("sqlite3", "", ExplainSlowQueries(time.Second))
+		original := f.options
+		f.Update(ConnExecContext(func(o *StepOptions) { o.Complete.Msg = "Exec" }))
+		f.Update(Sampling(0.5))
+		if len(f.opts) != 1 {
+			t.Errorf("Expected the options given by New only but got %d options", len(f.opts))
+		}
+		if actual := original.DriverOptions.ConnOptions.ExecContext.current().Complete.Msg; actual != "Exec" {
+			t.Errorf("Expected the previous update to be kept but got %s", actual)
+		}
+		if original.DriverOptions.ConnOptions.ExecContext.Complete.Msg != "Conn.ExecContext" {
+			t.Error("Expected the original options not to be modified")
+		}
+		if f.options.sampleRate != 0.5 {
+			t.Errorf("Unexpected sample rate: %v", f.options.sampleRate)
+		}
+	})
+
+	t.Run("operation levels", func(t *testing.T) {
+		t.Parallel()
+		f := New("sqlite3", "", OperationLevel(OperationDelete, LevelWarn))
+		original := f.options
+		f.Update(OperationLevel(OperationDelete, LevelError))
+		if lv := original.DriverOptions.ConnOptions.ExecContext.OperationLevels[OperationDelete]; lv != LevelWarn {
+			t.Errorf("Expected the original levels not to be modified but got %v", lv)
+		}
+		if lv := original.DriverOptions.ConnOptions.ExecContext.current().OperationLevels[OperationDelete]; lv != LevelError {
+			t.Errorf("Expected the updated level but got %v", lv)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+		f := New("sqlite3", "", ExplainSlowQueries(time.Second))
+		original := f.options
+		f.Update(ConnExecContext(func(o *StepOptions) { o.Complete.Msg = "Exec" }), Sampling(0))
+		f.Reset(ExplainSlowQueries(0))
+		if actual := original.DriverOptions.ConnOptions.ExecContext.current().Complete.Msg; actual != "Conn.ExecContext" {
+			t.Errorf("Expected the message to be reset but got %s", actual)
+		}
+		if !original.sampled() || original.explainThreshold() != 0 {
+			t.Errorf("Unexpected live options: %v", original.explainThreshold())
+		}
+		f.Update(Sampling(0))
+		if original.sampled() {
+			t.Error("Expected the update after reset to take effect")
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		t.Parallel()
+		f, conn := newFactoryForLiveTest(&bytes.Buffer{}, LogWriter(&syncBuffer{}))
+		var wg sync.WaitGroup
+		for i := range 4 {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				f.Update(ConnExecContext(func(o *StepOptions) { o.SetLevel(Level(i)) }))
+				f.Update(OperationLevel(OperationDelete, Level(i)))
+			}()
+			go func() {
+				defer wg.Done()
+				exec(t, conn)
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestFactoryConfigHandler(t *testing.T) {
+	t.Parallel()
+	f := New("sqlite3", "", ExplainSlowQueries(time.Second))
+	h := f.ConfigHandler()
+
+	serve := func(method, body string) (*httptest.ResponseRecorder, *Config) {
+		req := httptest.NewRequest(method, "/", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			return rec, nil
+		}
+		var c Config
+		if err := json.Unmarshal(rec.Body.Bytes(), &c); err != nil {
+			t.Fatal(err)
+		}
+		return rec, &c
+	}
+
+	_, c := serve(http.MethodGet, "")
+	if c == nil || time.Duration(*c.ExplainSlowQueries) != time.Second || *c.SampleRate != 1 {
+		t.Fatalf("Unexpected config: %+v", c)
+	}
+	if exec := c.Steps[StepConnExecContext]; exec == nil || *exec.Complete.Level != LevelInfo || exec.Complete.Msg != "Conn.ExecContext" {
+		t.Errorf("Unexpected step config: %+v", exec)
+	}
+	if len(c.Steps) != len(steps) {
+		t.Errorf("Expected %d steps but got %d", len(steps), len(c.Steps))
+	}
+
+	_, c = serve(http.MethodPatch, `{"explain_slow_queries": "100ms", "sample_rate": 0.5, "steps": {"Conn.*Context": {"level": "DEBUG"}}}`)
+	if c == nil || time.Duration(*c.ExplainSlowQueries) != 100*time.Millisecond || *c.SampleRate != 0.5 {
+		t.Fatalf("Unexpected config: %+v", c)
+	}
+	if exec := c.Steps[StepConnExecContext]; *exec.Complete.Level != LevelDebug || *exec.Start.Level != LevelTrace {
+		t.Errorf("Unexpected step config: %+v %+v", exec.Start, exec.Complete)
+	}
+	if f.options.DriverOptions.ConnOptions.ExecContext.current().Complete.Level != LevelDebug {
+		t.Error("Expected the level to be updated")
+	}
+
+	_, c = serve(http.MethodPatch, `{"sample_rate": 0.25}`)
+	if c == nil || time.Duration(*c.ExplainSlowQueries) != 100*time.Millisecond || *c.Steps[StepConnExecContext].Complete.Level != LevelDebug {
+		t.Fatalf("Expected PATCH to keep the previous updates: %+v", c)
+	}
+
+	_, c = serve(http.MethodPut, `{"explain_slow_queries": "0s"}`)
+	if c == nil || *c.ExplainSlowQueries != 0 || *c.SampleRate != 1 || *c.Steps[StepConnExecContext].Complete.Level != LevelInfo {
+		t.Fatalf("Expected PUT to reset the previous updates: %+v", c)
+	}
+
+	if rec, _ := serve(http.MethodPut, `{"sample_rate": 2}`); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "sample_rate") {
+		t.Errorf("Unexpected response: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec, _ := serve(http.MethodPatch, `{"name": "`+strings.Repeat("x", configHandlerMaxBytes)+`"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected the large body to be rejected but got %d", rec.Code)
+	}
+	if rec, _ := serve(http.MethodDelete, ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Unexpected response: %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -23,7 +23,7 @@ See the following example for usage:
 
 [StepOptions]: sets the options for logging behavior.
 
-[SetStepEventMsgBuilder]: sets the function to format the step name.
+[StepEventMsg]: sets the function to format the step name.
 
 [sql.Open]: https://pkg.go.dev/database/sql#Open
 */
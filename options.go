@@ -1,6 +1,21 @@
 package sqlslog
 
+import (
+	"log/slog"
+	"sync"
+)
+
 type options struct {
+	// msgb is the builder of the messages of the step events.
+	msgb StepEventMsgBuilder
+
+	// dialectGiven is true if the dialect is given by UseDialect.
+	dialectGiven bool
+
+	// attrs is the attributes added to all the logs of the database by Name and Attrs.
+	attrs    []slog.Attr
+	dsnAttrs bool
+
 	stepLoggerOptions
 	DriverOptions *driverOptions
 	SlogOptions   *slogOptions
@@ -8,28 +23,101 @@ type options struct {
 }
 
 func newDefaultOptions(driverName string, msgb StepEventMsgBuilder) *options {
-	return &options{
+	o := &options{
+		msgb:              msgb,
 		stepLoggerOptions: defaultStepLoggerOptions(),
 		DriverOptions:     defaultDriverOptions(driverName, msgb),
 		SlogOptions:       defaultSlogOptions(),
 		Open:              *defaultStepOptions(msgb, StepSqlslogOpen, LevelInfo),
 	}
+	o.setDialect(dialectOf(driverName))
+	return o
+}
+
+// stepOption returns an option which calls f with the options of the step.
+func stepOption(step Step, f func(*StepOptions)) Option {
+	return func(o *options) {
+		for _, s := range o.stepOptions() {
+			if s.step == step {
+				f(s)
+			}
+		}
+	}
+}
+
+// stepOptions returns the options of all the steps.
+func (o *options) stepOptions() []*StepOptions {
+	d := o.DriverOptions
+	c := d.ConnOptions
+	return []*StepOptions{
+		&o.Open,
+		&d.Open, &d.OpenConnector, &d.ConnectorOptions.Connect,
+		&c.Begin, &c.BeginTx, &c.Close, &c.IsValid, &c.Prepare, &c.PrepareContext,
+		&c.ResetSession, &c.Ping, &c.ExecContext, &c.QueryContext,
+		&c.TxOptions.Commit, &c.TxOptions.Rollback,
+		&c.StmtOptions.Close, &c.StmtOptions.Exec, &c.StmtOptions.Query,
+		&c.StmtOptions.ExecContext, &c.StmtOptions.QueryContext,
+		&c.RowsOptions.Close, &c.RowsOptions.Next, &c.RowsOptions.NextResultSet,
+	}
 }
 
 // Option is a function that sets an option on the options struct.
 type Option func(*options)
 
-var stepEventMsgBuilder = StepEventMsgWithoutEventName
-
-// SetStepEventMsgBuilder sets the builder for the step event message used in logs.
-// If not set, the default is StepLogMsgWithEventName.
-func SetStepEventMsgBuilder(f StepEventMsgBuilder) { stepEventMsgBuilder = f }
+var (
+	stepEventMsgBuilder   StepEventMsgBuilder = StepEventMsgWithoutEventName
+	stepEventMsgBuilderMu sync.RWMutex
+)
+
+// SetStepEventMsgBuilder sets the builder for the step event message used in logs
+// of the factories created after the call without [StepEventMsg] option.
+// If not set, the default is StepEventMsgWithoutEventName.
+//
+// Deprecated: SetStepEventMsgBuilder affects every factory in the process. Use [StepEventMsg] instead.
+func SetStepEventMsgBuilder(f StepEventMsgBuilder) {
+	stepEventMsgBuilderMu.Lock()
+	defer stepEventMsgBuilderMu.Unlock()
+	stepEventMsgBuilder = f
+}
+
+func defaultStepEventMsgBuilder() StepEventMsgBuilder {
+	stepEventMsgBuilderMu.RLock()
+	defer stepEventMsgBuilderMu.RUnlock()
+	return stepEventMsgBuilder
+}
+
+// StepEventMsg sets the builder for the step event messages such as [StepEventMsgWithEventName] and [StepEventMsgDotted].
+// The messages which are customized by the other options are kept regardless of the order of the options.
+// The default is StepEventMsgWithoutEventName.
+func StepEventMsg(f StepEventMsgBuilder) Option {
+	return func(o *options) { o.setStepEventMsgBuilder(f) }
+}
+
+func (o *options) setStepEventMsgBuilder(f StepEventMsgBuilder) {
+	for _, s := range o.stepOptions() {
+		for event, e := range map[Event]*EventOptions{
+			EventStart:    &s.Start,
+			EventError:    &s.Error,
+			EventComplete: &s.Complete,
+			EventCanceled: &s.Canceled,
+			EventTimeout:  &s.Timeout,
+		} {
+			if e.Msg == o.msgb(s.step, event) {
+				e.Msg = f(s.step, event)
+			}
+		}
+	}
+	o.msgb = f
+}
 
 func newOptions(driverName string, opts ...Option) *options {
-	o := newDefaultOptions(driverName, stepEventMsgBuilder)
+	o := newDefaultOptions(driverName, defaultStepEventMsgBuilder())
 	for _, opt := range opts {
 		opt(o)
 	}
+	o.fillDialectErrorHandlers()
+	o.stepLoggerOptions.live = &liveOptions{}
+	o.stepLoggerOptions.live.store(&o.stepLoggerOptions)
 	return o
 }
 
@@ -43,6 +131,11 @@ func ConnClose(f func(*StepOptions)) Option {
 	return func(o *options) { f(&o.DriverOptions.ConnOptions.Close) }
 }
 
+// Set the options for Conn.IsValid.
+func ConnIsValid(f func(*StepOptions)) Option {
+	return func(o *options) { f(&o.DriverOptions.ConnOptions.IsValid) }
+}
+
 // Set the options for Conn.Prepare.
 func ConnPrepare(f func(*StepOptions)) Option {
 	return func(o *options) { f(&o.DriverOptions.ConnOptions.Prepare) }
@@ -17,6 +17,21 @@ func ExampleHandlerFunc() {
 	logger.InfoContext(ctx, "Hello, World!")
 }
 
+func ExampleStepEventMsg() {
+	dsn := "dummy-dsn"
+	ctx := context.TODO()
+	db, logger, _ := sqlslog.Open(ctx, "mock", dsn,
+		sqlslog.StepEventMsg(sqlslog.StepEventMsgDotted),
+		sqlslog.LogReplaceAttr(removeTimeAndDuration), // for testing
+	)
+	defer db.Close()
+	logger.InfoContext(ctx, "Hello, World!")
+
+	// Output:
+	// level=INFO msg=sql.open.complete driver=mock dsn=dummy-dsn
+	// level=INFO msg="Hello, World!"
+}
+
 func ExampleSetStepEventMsgBuilder() {
 	sqlslog.SetStepEventMsgBuilder(func(step sqlslog.Step, event sqlslog.Event) string {
 		return "PRFIX:" + step.String() + "/" + event.String() + ":SUFFIX"
@@ -20,3 +20,51 @@ func TestSetStepEventMsgBuilder(t *testing.T) { // nolint:paralleltest
 		}
 	})
 }
+
+func TestOptionsStepOptions(t *testing.T) {
+	t.Parallel()
+	o := newOptions("sqlite3")
+	found := map[Step]bool{}
+	for _, s := range o.stepOptions() {
+		if found[s.step] {
+			t.Errorf("Duplicated step %s", s.step)
+		}
+		found[s.step] = true
+	}
+	for _, step := range steps {
+		if !found[step] {
+			t.Errorf("Missing step %s", step)
+		}
+	}
+}
+
+func TestStepEventMsg(t *testing.T) {
+	t.Parallel()
+
+	t.Run("builder", func(t *testing.T) {
+		t.Parallel()
+		opts := newOptions("dummy", StepEventMsg(StepEventMsgDotted))
+		if opts.DriverOptions.ConnOptions.Begin.Complete.Msg != "sql.conn.begin.complete" {
+			t.Errorf("unexpected value: %s", opts.DriverOptions.ConnOptions.Begin.Complete.Msg)
+		}
+		if opts.Open.Start.Msg != "sql.open.start" {
+			t.Errorf("unexpected value: %s", opts.Open.Start.Msg)
+		}
+	})
+
+	t.Run("customized messages are kept", func(t *testing.T) {
+		t.Parallel()
+		for _, opts := range [][]Option{
+			{StepEventMsg(StepEventMsgLowercase), ConnBegin(func(o *StepOptions) { o.Complete.Msg = "begin" })},
+			{ConnBegin(func(o *StepOptions) { o.Complete.Msg = "begin" }), StepEventMsg(StepEventMsgLowercase)},
+		} {
+			o := newOptions("dummy", opts...)
+			if o.DriverOptions.ConnOptions.Begin.Complete.Msg != "begin" {
+				t.Errorf("unexpected value: %s", o.DriverOptions.ConnOptions.Begin.Complete.Msg)
+			}
+			if o.DriverOptions.ConnOptions.Begin.Start.Msg != "conn.begin start" {
+				t.Errorf("unexpected value: %s", o.DriverOptions.ConnOptions.Begin.Start.Msg)
+			}
+		}
+	})
+}
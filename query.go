@@ -0,0 +1,115 @@
+package sqlslog
+
+import (
+	"log/slog"
+	"sync"
+)
+
+type queryOptions struct {
+	syntax         SQLSyntax
+	fingerprint    bool
+	fingerprintKey string
+	name           bool
+	nameKey        string
+	kindKey        string
+	stripName      bool
+	operation      bool
+	operationKey   string
+	tables         bool
+	tablesKey      string
+	semconv        bool
+}
+
+func defaultQueryOptions() queryOptions {
+	return queryOptions{
+		syntax:         SQLSyntaxGeneric,
+		fingerprintKey: QueryFingerprintKeyDefault,
+		nameKey:        QueryNameKeyDefault,
+		kindKey:        QueryKindKeyDefault,
+		operationKey:   QueryOperationKeyDefault,
+		tablesKey:      QueryTablesKeyDefault,
+	}
+}
+
+// queryInfo is the information about the query which is run by steps.
+// The information derived from the query is calculated lazily and only once.
+type queryInfo struct {
+	text   string
+	syntax SQLSyntax
+	// name is the name annotation of the query. It is nil if the query has no name annotation.
+	name *queryName
+	// logged is the query to be logged.
+	logged string
+
+	fingerprintOnce sync.Once
+	fingerprint     string
+
+	classifyOnce sync.Once
+	operation    Operation
+	keyword      string
+	tables       []string
+}
+
+func newQueryInfo(query string, opts *queryOptions) *queryInfo {
+	r := &queryInfo{text: query, syntax: opts.syntax, logged: query}
+	if name, ok := parseQueryName(query); ok {
+		r.name = name
+		if opts.stripName {
+			r.logged = name.stripped
+		}
+	}
+	return r
+}
+
+// Fingerprint returns the fingerprint of the query.
+func (q *queryInfo) Fingerprint() string {
+	q.fingerprintOnce.Do(func() { q.fingerprint = queryFingerprint(q.syntax, q.text) })
+	return q.fingerprint
+}
+
+// Operation returns the operation of the query.
+func (q *queryInfo) Operation() Operation {
+	q.classify()
+	return q.operation
+}
+
+// Keyword returns the first keyword of the main statement such as SELECT, REPLACE and CREATE.
+// It returns an empty string if it's not found.
+func (q *queryInfo) Keyword() string {
+	q.classify()
+	return q.keyword
+}
+
+// Tables returns the tables which the query touches.
+func (q *queryInfo) Tables() []string {
+	q.classify()
+	return q.tables
+}
+
+func (q *queryInfo) classify() {
+	q.classifyOnce.Do(func() { q.operation, q.keyword, q.tables = classifyQuery(q.syntax, q.text) })
+}
+
+// attrs returns the attributes of the query to be logged.
+func (o *queryOptions) attrs(q *queryInfo) []interface{} {
+	var r []interface{}
+	if o.fingerprint {
+		r = append(r, slog.String(o.fingerprintKey, q.Fingerprint()))
+	}
+	if o.name && q.name != nil {
+		r = append(r, slog.String(o.nameKey, q.name.name))
+		if q.name.kind != "" {
+			r = append(r, slog.String(o.kindKey, q.name.kind))
+		}
+	}
+	if o.operation {
+		r = append(r, slog.String(o.operationKey, q.Operation().String()))
+	}
+	if o.tables {
+		r = append(r, slog.Any(o.tablesKey, q.Tables()))
+	}
+	if o.semconv {
+		r = append(r, semconvQueryAttrs(q)...)
+	}
+	return r
+}
@@ -0,0 +1,170 @@
+package sqlslog
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+)
+
+// QueryFingerprint sets whether the query fingerprint is logged with the steps which run a query.
+// The query fingerprint is a short stable ID of the normalized query.
+// Queries which differ only in comments, whitespaces, literals, placeholders
+// or the length of IN-lists have the same fingerprint.
+// The default is false.
+func QueryFingerprint(v bool) Option {
+	return func(o *options) { o.stepLoggerOptions.queryOptions.fingerprint = v }
+}
+
+// QueryFingerprintKey sets the key for the query fingerprint.
+// The default is QueryFingerprintKeyDefault.
+func QueryFingerprintKey(key string) Option {
+	return func(o *options) { o.stepLoggerOptions.queryOptions.fingerprintKey = key }
+}
+
+// QueryFingerprintKeyDefault is the default key for the query fingerprint.
+const QueryFingerprintKeyDefault = "query_fingerprint"
+
+// queryFingerprint returns a short stable ID for the given query.
+func queryFingerprint(syntax SQLSyntax, query string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(normalizeQuery(syntax, query)))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// normalizedPlaceholder is the placeholder which replaces literals and placeholders in normalized queries.
+const normalizedPlaceholder = "?"
+
+// normalizeQuery returns the shape of the query.
+// It strips comments, collapses whitespaces, lowercases keywords and identifiers without quotes,
+// replaces literals and placeholders with ?, replaces IN-lists with a single placeholder,
+// and collapses repeated VALUES tuples into one.
+func normalizeQuery(syntax SQLSyntax, query string) string {
+	tokens := tokenizeSQL(syntax, query)
+	words := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		switch {
+		case t.kind == sqlTokenSpace, t.kind == sqlTokenComment:
+			continue
+		case t.isLiteral():
+			words = append(words, normalizedPlaceholder)
+		case t.kind == sqlTokenWord:
+			words = append(words, strings.ToLower(t.text))
+		default:
+			words = append(words, t.text)
+		}
+	}
+	for len(words) > 0 && words[len(words)-1] == ";" {
+		words = words[:len(words)-1]
+	}
+	return joinNormalizedWords(collapseValuesTuples(collapseInLists(words)))
+}
+
+// collapseInLists replaces IN-lists which contain only placeholders with IN (?).
+func collapseInLists(words []string) []string {
+	r := make([]string, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		r = append(r, words[i])
+		if words[i] != "in" || i+1 >= len(words) || words[i+1] != "(" {
+			continue
+		}
+		end, ok := placeholderListEnd(words, i+2)
+		if !ok {
+			continue
+		}
+		r = append(r, "(", normalizedPlaceholder, ")")
+		i = end
+	}
+	return r
+}
+
+// placeholderListEnd returns the index of ")" which closes the list starting at the given index
+// if the list consists of placeholders separated by commas.
+func placeholderListEnd(words []string, start int) (int, bool) {
+	expectPlaceholder := true
+	for i := start; i < len(words); i++ {
+		switch {
+		case expectPlaceholder && words[i] == normalizedPlaceholder:
+			expectPlaceholder = false
+		case !expectPlaceholder && words[i] == ",":
+			expectPlaceholder = true
+		case !expectPlaceholder && words[i] == ")":
+			return i, true
+		default:
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// collapseValuesTuples removes the VALUES tuples which are the same as the first one.
+func collapseValuesTuples(words []string) []string {
+	r := make([]string, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		r = append(r, words[i])
+		if words[i] != "values" {
+			continue
+		}
+		first, ok := tupleEnd(words, i+1)
+		if !ok {
+			continue
+		}
+		r = append(r, words[i+1:first+1]...)
+		tuple := words[i+1 : first+1]
+		i = first
+		for i+1 < len(words) && words[i+1] == "," {
+			next, ok := tupleEnd(words, i+2)
+			if !ok || !slices.Equal(tuple, words[i+2:next+1]) {
+				break
+			}
+			i = next
+		}
+	}
+	return r
+}
+
+// tupleEnd returns the index of ")" which closes "(" at the given index.
+func tupleEnd(words []string, start int) (int, bool) {
+	if start >= len(words) || words[start] != "(" {
+		return 0, false
+	}
+	depth := 0
+	for i := start; i < len(words); i++ {
+		switch words[i] {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// joinNormalizedWords joins the words with a single space except around punctuations like "(", ")", "," and ".".
+func joinNormalizedWords(words []string) string {
+	var b strings.Builder
+	for i, w := range words {
+		if i > 0 && needsSpaceBetween(words[i-1], w) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(w)
+	}
+	return b.String()
+}
+
+func needsSpaceBetween(prev, cur string) bool {
+	switch cur {
+	case ",", ")", ".", ";", "::":
+		return false
+	case "(":
+		return !isSQLIdentStart(prev[0])
+	}
+	switch prev {
+	case "(", ".", "::":
+		return false
+	}
+	return true
+}
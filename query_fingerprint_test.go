@@ -0,0 +1,88 @@
+package sqlslog
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		syntax   SQLSyntax
+		query    string
+		expected string
+	}{
+		{
+			syntax:   SQLSyntaxGeneric,
+			query:    "-- name: GetUser :one\nSELECT id, name\n  FROM users\n WHERE id = 123 AND name = 'foo';",
+			expected: "select id, name from users where id = ? and name = ?",
+		},
+		{
+			syntax:   SQLSyntaxPostgres,
+			query:    "SELECT * FROM users WHERE id IN ($1, $2, $3) AND created_at > $4::timestamp",
+			expected: "select * from users where id in(?) and created_at > ?::timestamp",
+		},
+		{
+			syntax:   SQLSyntaxMySQL,
+			query:    "INSERT INTO `users` (name, age) VALUES (?, ?), (?, ?), (?, ?) # bulk",
+			expected: "insert into `users` (name, age) values(?, ?)",
+		},
+		{
+			syntax:   SQLSyntaxSQLite,
+			query:    "UPDATE users SET name = :name WHERE id = ?1",
+			expected: "update users set name = ? where id = ?",
+		},
+		{
+			syntax:   SQLSyntaxGeneric,
+			query:    "SELECT count(*) FROM t WHERE a IN (SELECT b FROM u)",
+			expected: "select count(*) from t where a in(select b from u)",
+		},
+		{
+			syntax:   SQLSyntaxGeneric,
+			query:    "INSERT INTO t (a) VALUES (1), (now())",
+			expected: "insert into t(a) values(?), (now())",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			t.Parallel()
+			if actual := normalizeQuery(tc.syntax, tc.query); actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestQueryFingerprint(t *testing.T) {
+	t.Parallel()
+	a := queryFingerprint(SQLSyntaxPostgres, "SELECT id FROM users WHERE id IN ($1, $2)")
+	if len(a) != 16 {
+		t.Fatalf("Unexpected length: %q", a)
+	}
+	if b := queryFingerprint(SQLSyntaxPostgres, "select id\n  from users /* x */ where id in ($1)"); a != b {
+		t.Fatalf("Expected %q, got %q", a, b)
+	}
+	if c := queryFingerprint(SQLSyntaxPostgres, "SELECT name FROM users WHERE id IN ($1, $2)"); a == c {
+		t.Fatalf("Expected different fingerprints, got %q", c)
+	}
+}
+
+func TestQueryFingerprintOption(t *testing.T) {
+	t.Parallel()
+	buf := bytes.NewBuffer(nil)
+	opts := newOptions("sqlite3", QueryFingerprint(true), QueryFingerprintKey("fp"))
+	logger := newStepLogger(slog.New(NewTextHandler(buf, nil)), opts.stepLoggerOptions)
+	conn := wrapConn(newMockErrConn(nil), logger, opts.DriverOptions.ConnOptions)
+	query := "DELETE FROM users WHERE id = ?"
+	if _, err := conn.(driver.ExecerContext).ExecContext(context.Background(), query, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "fp=" + queryFingerprint(SQLSyntaxSQLite, query)
+	if !strings.Contains(buf.String(), expected) {
+		t.Fatalf("Expected %q in %q", expected, buf.String())
+	}
+}
@@ -0,0 +1,99 @@
+package sqlslog
+
+import "strings"
+
+// QueryName sets whether the query name and the query kind are logged with the steps which run a query.
+// They are parsed from the leading comment of the query in sqlc style like `-- name: ListAuthors :many`
+// or in generic style like `/* name: ListAuthors */`.
+// The query kind is the annotation which starts with a colon such as `:one`, `:many` and `:exec`.
+// Queries without the comment have neither the query name nor the query kind.
+// The default is false.
+func QueryName(v bool) Option {
+	return func(o *options) { o.stepLoggerOptions.queryOptions.name = v }
+}
+
+// QueryNameKey sets the key for the query name.
+// The default is QueryNameKeyDefault.
+func QueryNameKey(key string) Option {
+	return func(o *options) { o.stepLoggerOptions.queryOptions.nameKey = key }
+}
+
+// QueryKindKey sets the key for the query kind.
+// The default is QueryKindKeyDefault.
+func QueryKindKey(key string) Option {
+	return func(o *options) { o.stepLoggerOptions.queryOptions.kindKey = key }
+}
+
+// StripQueryName sets whether the comment for the query name is removed from the query in logs.
+// The query which is sent to the database is not changed.
+// The default is false.
+func StripQueryName(v bool) Option {
+	return func(o *options) { o.stepLoggerOptions.queryOptions.stripName = v }
+}
+
+const (
+	QueryNameKeyDefault = "query_name"
+	QueryKindKeyDefault = "query_kind"
+)
+
+// queryName is the name annotation of the query.
+type queryName struct {
+	name string
+	kind string
+	// stripped is the query without the comment for the name.
+	stripped string
+}
+
+const queryNamePrefix = "name:"
+
+// parseQueryName parses the name annotation in the leading comments of the query.
+// It returns false if the query has no name annotation.
+func parseQueryName(query string) (*queryName, bool) {
+	const spaces = " \t\r\n"
+	pos := 0
+	for {
+		start := len(query) - len(strings.TrimLeft(query[pos:], spaces))
+		rest := query[start:]
+		var body string
+		var end int
+		switch {
+		case strings.HasPrefix(rest, "--"):
+			end = strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			body = rest[2:end]
+		case strings.HasPrefix(rest, "/*"):
+			i := strings.Index(rest[2:], "*/")
+			if i < 0 {
+				return nil, false
+			}
+			end = i + 2
+			body = rest[2:end]
+			end += 2
+		default:
+			return nil, false
+		}
+		if r, ok := newQueryName(body); ok {
+			r.stripped = query[:start] + strings.TrimLeft(rest[end:], spaces)
+			return r, true
+		}
+		pos = start + end
+	}
+}
+
+func newQueryName(commentBody string) (*queryName, bool) {
+	body := strings.TrimSpace(commentBody)
+	if !strings.HasPrefix(body, queryNamePrefix) {
+		return nil, false
+	}
+	fields := strings.Fields(body[len(queryNamePrefix):])
+	if len(fields) == 0 {
+		return nil, false
+	}
+	r := &queryName{name: fields[0]}
+	if len(fields) > 1 && strings.HasPrefix(fields[1], ":") {
+		r.kind = fields[1]
+	}
+	return r, true
+}
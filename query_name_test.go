@@ -0,0 +1,106 @@
+package sqlslog
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseQueryName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		query    string
+		expected *queryName
+	}{
+		{
+			query:    "-- name: ListAuthors :many\nSELECT id, name, bio FROM authors\nORDER BY name\n",
+			expected: &queryName{name: "ListAuthors", kind: ":many", stripped: "SELECT id, name, bio FROM authors\nORDER BY name\n"},
+		},
+		{
+			query:    "\n  /* name: GetAuthor */ SELECT * FROM authors WHERE id = ?",
+			expected: &queryName{name: "GetAuthor", stripped: "\n  SELECT * FROM authors WHERE id = ?"},
+		},
+		{
+			query:    "-- comment\n-- name: DeleteAuthor :exec\nDELETE FROM authors WHERE id = ?",
+			expected: &queryName{name: "DeleteAuthor", kind: ":exec", stripped: "-- comment\nDELETE FROM authors WHERE id = ?"},
+		},
+		{query: "SELECT 1 -- name: NotLeading"},
+		{query: "-- name:\nSELECT 1"},
+		{query: "/* name: Unterminated"},
+		{query: "/*"},
+		{query: "/*/ note */ SELECT 1"},
+		{query: "/**/ SELECT 1"},
+		{
+			query:    "/*/ note */ /* name: AfterSlash */ SELECT 1",
+			expected: &queryName{name: "AfterSlash", stripped: "/*/ note */ SELECT 1"},
+		},
+		{
+			query:    "/**/ -- name: AfterEmpty :one\nSELECT 1",
+			expected: &queryName{name: "AfterEmpty", kind: ":one", stripped: "/**/ SELECT 1"},
+		},
+		{query: "-- just a comment"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			t.Parallel()
+			actual, ok := parseQueryName(tc.query)
+			if tc.expected == nil {
+				if ok {
+					t.Fatalf("Expected no name, got %+v", actual)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("Expected name")
+			}
+			if *actual != *tc.expected {
+				t.Fatalf("Expected %+v, got %+v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestQueryNameOption(t *testing.T) {
+	t.Parallel()
+	query := "-- name: CreateAuthor :one\nINSERT INTO authors (name) VALUES (?)"
+	run := func(t *testing.T, query string, opts ...Option) string {
+		t.Helper()
+		buf := bytes.NewBuffer(nil)
+		o := newOptions("sqlite3", opts...)
+		logger := newStepLogger(slog.New(NewTextHandler(buf, nil)), o.stepLoggerOptions)
+		conn := wrapConn(newMockErrConn(nil), logger, o.DriverOptions.ConnOptions)
+		if _, err := conn.(driver.ExecerContext).ExecContext(context.Background(), query, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return buf.String()
+	}
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+		if out := run(t, query); strings.Contains(out, "query_name=") {
+			t.Fatalf("Unexpected query_name in %q", out)
+		}
+	})
+	t.Run("enabled", func(t *testing.T) {
+		t.Parallel()
+		out := run(t, query, QueryName(true), StripQueryName(true))
+		for _, s := range []string{
+			"query_name=CreateAuthor",
+			"query_kind=:one",
+			`query="INSERT INTO authors (name) VALUES (?)"`,
+		} {
+			if !strings.Contains(out, s) {
+				t.Errorf("Expected %q in %q", s, out)
+			}
+		}
+	})
+	t.Run("comment opened with slash", func(t *testing.T) {
+		t.Parallel()
+		out := run(t, "/*/ note */ SELECT 1", QueryName(true))
+		if strings.Contains(out, "query_name=") {
+			t.Fatalf("Unexpected query_name in %q", out)
+		}
+	})
+}
@@ -0,0 +1,277 @@
+package sqlslog
+
+import (
+	"slices"
+	"strings"
+)
+
+// Operation is the type of the statement.
+type Operation string
+
+const (
+	OperationSelect Operation = "SELECT" // SELECT statements including WITH ... SELECT.
+	OperationInsert Operation = "INSERT" // INSERT and REPLACE statements.
+	OperationUpdate Operation = "UPDATE" // UPDATE statements.
+	OperationDelete Operation = "DELETE" // DELETE statements.
+	OperationDDL    Operation = "DDL"    // CREATE, ALTER, DROP, TRUNCATE and RENAME statements.
+	OperationBegin  Operation = "BEGIN"  // BEGIN and START TRANSACTION statements.
+	OperationOther  Operation = "OTHER"  // Any other statements.
+)
+
+// String returns the string representation of the operation.
+func (op Operation) String() string {
+	return string(op)
+}
+
+// QueryOperation sets whether the operation of the query is logged with the steps which run a query.
+// See [Operation] for the operations.
+// The default is false.
+func QueryOperation(v bool) Option {
+	return func(o *options) { o.stepLoggerOptions.queryOptions.operation = v }
+}
+
+// QueryOperationKey sets the key for the operation of the query.
+// The default is QueryOperationKeyDefault.
+func QueryOperationKey(key string) Option {
+	return func(o *options) { o.stepLoggerOptions.queryOptions.operationKey = key }
+}
+
+// QueryTables sets whether the tables which the query touches are logged with the steps which run a query.
+// The tables are detected on a best-effort basis from the table names following FROM, JOIN, INTO, UPDATE and TABLE.
+// The default is false.
+func QueryTables(v bool) Option {
+	return func(o *options) { o.stepLoggerOptions.queryOptions.tables = v }
+}
+
+// QueryTablesKey sets the key for the tables which the query touches.
+// The default is QueryTablesKeyDefault.
+func QueryTablesKey(key string) Option {
+	return func(o *options) { o.stepLoggerOptions.queryOptions.tablesKey = key }
+}
+
+const (
+	QueryOperationKeyDefault = "operation"
+	QueryTablesKeyDefault    = "tables"
+)
+
+// OperationLevel sets the level for the given operation to the steps which run a query:
+// Conn.Prepare, Conn.PrepareContext, Conn.ExecContext, Conn.QueryContext,
+// Stmt.Exec, Stmt.Query, Stmt.ExecContext and Stmt.QueryContext.
+// See [StepOptions.SetOperationLevel] for details.
+func OperationLevel(op Operation, lv Level) Option {
+	return func(o *options) {
+		connOptions := o.DriverOptions.ConnOptions
+		stmtOptions := connOptions.StmtOptions
+		for _, stepOptions := range []*StepOptions{
+			&connOptions.Prepare,
+			&connOptions.PrepareContext,
+			&connOptions.ExecContext,
+			&connOptions.QueryContext,
+			&stmtOptions.Exec,
+			&stmtOptions.Query,
+			&stmtOptions.ExecContext,
+			&stmtOptions.QueryContext,
+		} {
+			stepOptions.SetOperationLevel(op, lv)
+		}
+	}
+}
+
+// classifyQuery returns the operation, the keyword of the main statement and the tables of the query.
+func classifyQuery(syntax SQLSyntax, query string) (Operation, string, []string) {
+	var tokens []sqlToken
+	for _, t := range tokenizeSQL(syntax, query) {
+		if t.kind != sqlTokenSpace && t.kind != sqlTokenComment {
+			tokens = append(tokens, t)
+		}
+	}
+	cteNames, main := skipCTEs(tokens)
+	for main < len(tokens) && tokens[main].text == "(" {
+		main++
+	}
+	return queryOperation(tokens, main), queryKeyword(tokens, main), queryTables(tokens, cteNames)
+}
+
+// skipCTEs returns the names of the common table expressions and the index of the main statement.
+func skipCTEs(tokens []sqlToken) ([]string, int) {
+	i := 0
+	for i < len(tokens) && tokens[i].text == "(" {
+		i++
+	}
+	if i >= len(tokens) || !tokens[i].isWord("WITH") {
+		return nil, i
+	}
+	i++
+	if i < len(tokens) && tokens[i].isWord("RECURSIVE") {
+		i++
+	}
+	var names []string
+	for i < len(tokens) {
+		names = append(names, unquoteSQLIdent(tokens[i].text))
+		// Skip to the body of the CTE
+		for i < len(tokens) && tokens[i].text != "(" {
+			i++
+		}
+		i = skipParens(tokens, i)
+		if i >= len(tokens) || tokens[i].text != "," {
+			break
+		}
+		i++
+	}
+	return names, i
+}
+
+// skipParens returns the index next to ")" which closes "(" at the given index.
+func skipParens(tokens []sqlToken, start int) int {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		switch tokens[i].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(tokens)
+}
+
+func queryOperation(tokens []sqlToken, main int) Operation {
+	if main >= len(tokens) || tokens[main].kind != sqlTokenWord {
+		return OperationOther
+	}
+	switch strings.ToUpper(tokens[main].text) {
+	case "SELECT", "VALUES", "TABLE":
+		return OperationSelect
+	case "INSERT", "REPLACE":
+		return OperationInsert
+	case "UPDATE":
+		return OperationUpdate
+	case "DELETE":
+		return OperationDelete
+	case "CREATE", "ALTER", "DROP", "TRUNCATE", "RENAME":
+		return OperationDDL
+	case "BEGIN":
+		return OperationBegin
+	case "START":
+		if main+1 < len(tokens) && tokens[main+1].isWord("TRANSACTION") {
+			return OperationBegin
+		}
+	}
+	return OperationOther
+}
+
+// queryKeyword returns the first keyword of the main statement in uppercase such as SELECT and REPLACE.
+// It returns an empty string if the statement doesn't start with a keyword.
+func queryKeyword(tokens []sqlToken, main int) string {
+	if main >= len(tokens) || tokens[main].kind != sqlTokenWord {
+		return ""
+	}
+	return strings.ToUpper(tokens[main].text)
+}
+
+// queryTables returns the tables following FROM, JOIN, INTO, UPDATE and TABLE except the given CTE names.
+func queryTables(tokens []sqlToken, cteNames []string) []string {
+	var r []string
+	add := func(name string) {
+		if !slices.Contains(cteNames, name) && !slices.Contains(r, name) {
+			r = append(r, name)
+		}
+	}
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].kind != sqlTokenWord {
+			continue
+		}
+		switch strings.ToUpper(tokens[i].text) {
+		case "FROM", "JOIN", "INTO", "UPDATE", "TABLE":
+		default:
+			continue
+		}
+		for {
+			j := skipTableModifiers(tokens, i+1)
+			name, next, ok := qualifiedName(tokens, j)
+			if !ok {
+				break
+			}
+			add(name)
+			// Comma separated tables like FROM a, b
+			k := skipAlias(tokens, next)
+			if k >= len(tokens) || tokens[k].text != "," || !tokens[i].isWord("FROM") {
+				break
+			}
+			i = k
+		}
+	}
+	return r
+}
+
+// skipTableModifiers skips the keywords between the keyword and the table name
+// such as IF NOT EXISTS, ONLY and LOW_PRIORITY.
+func skipTableModifiers(tokens []sqlToken, i int) int {
+	for i < len(tokens) && tokens[i].kind == sqlTokenWord {
+		switch strings.ToUpper(tokens[i].text) {
+		case "IF", "NOT", "EXISTS", "ONLY", "LOW_PRIORITY", "DELAYED", "HIGH_PRIORITY", "IGNORE", "LATERAL":
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// qualifiedName returns the name like schema.table starting at the given index.
+func qualifiedName(tokens []sqlToken, i int) (string, int, bool) {
+	var parts []string
+	for i < len(tokens) && (tokens[i].kind == sqlTokenWord || tokens[i].kind == sqlTokenQuotedIdent) {
+		if tokens[i].kind == sqlTokenWord && isSQLReservedWord(tokens[i].text) {
+			break
+		}
+		parts = append(parts, unquoteSQLIdent(tokens[i].text))
+		i++
+		if i+1 < len(tokens) && tokens[i].text == "." {
+			i++
+			continue
+		}
+		break
+	}
+	if len(parts) == 0 {
+		return "", i, false
+	}
+	return strings.Join(parts, "."), i, true
+}
+
+// skipAlias skips the alias like `AS t` or `t` following the table name.
+func skipAlias(tokens []sqlToken, i int) int {
+	if i < len(tokens) && tokens[i].isWord("AS") {
+		i++
+	}
+	if i < len(tokens) && (tokens[i].kind == sqlTokenQuotedIdent || tokens[i].kind == sqlTokenWord && !isSQLReservedWord(tokens[i].text)) {
+		i++
+	}
+	return i
+}
+
+var sqlReservedWords = map[string]struct{}{
+	"SELECT": {}, "FROM": {}, "WHERE": {}, "JOIN": {}, "INNER": {}, "LEFT": {}, "RIGHT": {}, "FULL": {},
+	"OUTER": {}, "CROSS": {}, "NATURAL": {}, "ON": {}, "USING": {}, "GROUP": {}, "ORDER": {}, "HAVING": {},
+	"LIMIT": {}, "OFFSET": {}, "UNION": {}, "EXCEPT": {}, "INTERSECT": {}, "SET": {}, "VALUES": {},
+	"RETURNING": {}, "AS": {}, "WITH": {}, "DEFAULT": {}, "FOR": {}, "WINDOW": {},
+}
+
+func isSQLReservedWord(s string) bool {
+	_, ok := sqlReservedWords[strings.ToUpper(s)]
+	return ok
+}
+
+// unquoteSQLIdent removes the quotes of the identifier like "name", `name` and [name].
+func unquoteSQLIdent(s string) string {
+	if len(s) >= 2 {
+		switch {
+		case s[0] == '"' && s[len(s)-1] == '"', s[0] == '`' && s[len(s)-1] == '`', s[0] == '[' && s[len(s)-1] == ']':
+			return s[1 : len(s)-1]
+		}
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+package sqlslog
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestClassifyQuery(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		query     string
+		operation Operation
+		keyword   string
+		tables    []string
+	}{
+		{"SELECT * FROM users u JOIN orders o ON u.id = o.user_id", OperationSelect, "SELECT", []string{"users", "orders"}},
+		{"select a.x from public.a, \"B\" as b where a.id = b.id", OperationSelect, "SELECT", []string{"public.a", "B"}},
+		{"WITH recent AS (SELECT * FROM orders) SELECT * FROM recent JOIN users ON true", OperationSelect, "SELECT", []string{"orders", "users"}},
+		{"WITH t AS (SELECT 1) DELETE FROM logs WHERE id IN (SELECT * FROM t)", OperationDelete, "DELETE", []string{"logs"}},
+		{"-- name: CreateAuthor :one\nINSERT INTO authors (name) VALUES (?)", OperationInsert, "INSERT", []string{"authors"}},
+		{"REPLACE INTO `kv` VALUES (?, ?)", OperationInsert, "REPLACE", []string{"kv"}},
+		{"UPDATE users SET name = ? WHERE id = ?", OperationUpdate, "UPDATE", []string{"users"}},
+		{"DELETE FROM users", OperationDelete, "DELETE", []string{"users"}},
+		{"CREATE TABLE IF NOT EXISTS test1 (id INTEGER PRIMARY KEY)", OperationDDL, "CREATE", []string{"test1"}},
+		{"DROP TABLE test1", OperationDDL, "DROP", []string{"test1"}},
+		{"BEGIN", OperationBegin, "BEGIN", nil},
+		{"START TRANSACTION", OperationBegin, "START", nil},
+		{"START SLAVE", OperationOther, "START", nil},
+		{"PRAGMA foreign_keys = ON", OperationOther, "PRAGMA", nil},
+		{"(SELECT 1) UNION (SELECT 2)", OperationSelect, "SELECT", nil},
+		{"", OperationOther, "", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			t.Parallel()
+			op, keyword, tables := classifyQuery(SQLSyntaxGeneric, tc.query)
+			if op != tc.operation {
+				t.Errorf("Expected %s, got %s", tc.operation, op)
+			}
+			if keyword != tc.keyword {
+				t.Errorf("Expected keyword %q, got %q", tc.keyword, keyword)
+			}
+			if !slices.Equal(tables, tc.tables) {
+				t.Errorf("Expected %v, got %v", tc.tables, tables)
+			}
+		})
+	}
+}
+
+func TestOperationLevel(t *testing.T) {
+	t.Parallel()
+	buf := bytes.NewBuffer(nil)
+	opts := newOptions("sqlite3",
+		OperationLevel(OperationDDL, LevelWarn),
+		QueryOperation(true),
+		QueryTables(true),
+	)
+	if lv := opts.DriverOptions.ConnOptions.StmtOptions.ExecContext.OperationLevels[OperationDDL]; lv != LevelWarn {
+		t.Fatalf("Expected %v, got %v", LevelWarn, lv)
+	}
+	logger := newStepLogger(slog.New(NewTextHandler(buf, nil)), opts.stepLoggerOptions)
+	conn := wrapConn(newMockErrConn(nil), logger, opts.DriverOptions.ConnOptions)
+	execer := conn.(driver.ExecerContext)
+
+	if _, err := execer.ExecContext(context.Background(), "DROP TABLE users", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, s := range []string{"level=INFO msg=Conn.ExecContext", "level=WARN msg=Conn.ExecContext", "operation=DDL", "tables=[users]"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("Expected %q in %q", s, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if _, err := execer.ExecContext(context.Background(), "DELETE FROM users", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if out := buf.String(); !strings.HasPrefix(out[strings.Index(out, "level="):], "level=INFO") || strings.Contains(out, "WARN") {
+		t.Errorf("Unexpected log: %q", out)
+	}
+}
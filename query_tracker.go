@@ -0,0 +1,139 @@
+package sqlslog
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TrackQueries returns a context with a query recorder which counts the queries by fingerprint
+// running under the context. Typically it is used for each HTTP request.
+// When the number of the queries with the same fingerprint reaches the threshold,
+// sqlslog logs an "N+1 suspected" event at [LevelWarn] with the fingerprint, the count and the call site.
+// The event is logged only once for each fingerprint in the context.
+// See [NPlusOneThreshold] for the threshold.
+func TrackQueries(ctx context.Context) context.Context {
+	if queryTrackerFrom(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKeyQueryTracker, &queryTracker{counts: map[string]int{}, duplicates: map[string]*DuplicateQuery{}})
+}
+
+// NPlusOneThreshold sets the number of the queries with the same fingerprint
+// to report an "N+1 suspected" event in the context given by [TrackQueries].
+// If it's zero or negative, the event is never logged.
+// The default is NPlusOneThresholdDefault.
+func NPlusOneThreshold(n int) Option {
+	return func(o *options) { o.stepLoggerOptions.nPlusOneThreshold = n }
+}
+
+// NPlusOneThresholdDefault is the default threshold for [NPlusOneThreshold].
+const NPlusOneThresholdDefault = 10
+
+const (
+	nPlusOneMsg     = "N+1 suspected"
+	countKey        = "count"
+	callSiteKey     = "call_site"
+	nPlusOneLevel   = LevelWarn
+	callersMaxDepth = 32
+)
+
+// queryTracker is the request-scoped recorder of queries.
+type queryTracker struct {
+	mu         sync.Mutex
+	counts     map[string]int
+	duplicates map[string]*DuplicateQuery
+	budget     BudgetReport
+}
+
+func queryTrackerFrom(ctx context.Context) *queryTracker {
+	if ctx == nil {
+		return nil
+	}
+	if v, ok := ctx.Value(contextKeyQueryTracker).(*queryTracker); ok {
+		return v
+	}
+	return nil
+}
+
+// record counts the query with the given fingerprint and returns the count.
+func (t *queryTracker) record(fingerprint string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counts[fingerprint]++
+	t.budget.Queries++
+	return t.counts[fingerprint]
+}
+
+// trackStep records the step which runs under the context.
+func (x *stepLogger) trackStep(ctx context.Context, step *StepOptions, d time.Duration, err error) {
+	tracker := queryTrackerFrom(ctx)
+	if tracker == nil {
+		return
+	}
+	tracker.addDuration(d, x.options.semconv)
+	if step.step == StepRowsNext && err == nil {
+		tracker.addRowsRead(1)
+	}
+	if x.query != nil && step.step.runsQuery() && !errors.Is(err, driver.ErrSkip) {
+		x.trackQuery(ctx, tracker)
+		x.trackDuplicate(ctx, tracker, d)
+	}
+}
+
+// trackQuery records the query and logs an N+1 event if needed.
+func (x *stepLogger) trackQuery(ctx context.Context, tracker *queryTracker) {
+	fingerprint := x.query.Fingerprint()
+	count := tracker.record(fingerprint)
+	if x.options.nPlusOneThreshold <= 0 || count != x.options.nPlusOneThreshold {
+		return
+	}
+	x.Log(ctx, slog.Level(nPlusOneLevel), nPlusOneMsg, append([]interface{}{
+		slog.String(x.options.queryOptions.fingerprintKey, fingerprint),
+		slog.Int(countKey, count),
+		slog.String(callSiteKey, callSite()),
+	}, x.queryAttrs()...)...)
+}
+
+// callSite returns the first caller outside of sqlslog, database/sql and runtime packages.
+func callSite() string {
+	pcs := make([]uintptr, callersMaxDepth)
+	n := runtime.Callers(2, pcs) // nolint:mnd
+	return callSiteOf(pcs[:n])
+}
+
+// callSiteOf returns the first caller outside of sqlslog, database/sql and runtime packages in the given stack.
+func callSiteOf(pcs []uintptr) string {
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if !isInternalFrame(frame) {
+			return fmt.Sprintf("%s:%d %s", frame.File, frame.Line, frame.Function)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+const packagePath = "github.com/akm/sql-slog."
+
+func isInternalFrame(frame runtime.Frame) bool {
+	switch {
+	case strings.HasPrefix(frame.Function, packagePath):
+		return !strings.HasSuffix(frame.File, "_test.go")
+	case strings.HasPrefix(frame.Function, "database/sql."),
+		strings.HasPrefix(frame.Function, "runtime."),
+		strings.HasPrefix(frame.Function, "runtime/"),
+		strings.HasPrefix(frame.Function, "context."):
+		return true
+	default:
+		return false
+	}
+}
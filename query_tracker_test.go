@@ -0,0 +1,62 @@
+package sqlslog
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestTrackQueries(t *testing.T) {
+	t.Parallel()
+	buf := bytes.NewBuffer(nil)
+	opts := newOptions("sqlite3", NPlusOneThreshold(3), DuplicateQueryThreshold(0))
+	handler := NewTextHandler(buf, &slog.HandlerOptions{Level: LevelWarn})
+	logger := newStepLogger(slog.New(handler), opts.stepLoggerOptions)
+	conn := wrapConn(newMockErrConn(nil), logger, opts.DriverOptions.ConnOptions)
+	execer := conn.(driver.ExecerContext)
+
+	ctx := TrackQueries(context.Background())
+	if TrackQueries(ctx) != ctx {
+		t.Fatal("Expected the same context")
+	}
+	for i := range 5 {
+		if _, err := execer.ExecContext(ctx, "UPDATE users SET n = ? WHERE id = ?", []driver.NamedValue{{Ordinal: 1, Value: i}}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := execer.ExecContext(ctx, "DELETE FROM users", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", buf.String())
+	}
+	for _, s := range []string{
+		`msg="N+1 suspected"`,
+		"query_fingerprint=" + queryFingerprint(SQLSyntaxSQLite, "UPDATE users SET n = ? WHERE id = ?"),
+		"count=3",
+		"query_tracker_test.go",
+	} {
+		if !strings.Contains(lines[0], s) {
+			t.Errorf("Expected %q in %q", s, lines[0])
+		}
+	}
+
+	t.Run("without tracking", func(t *testing.T) {
+		t.Parallel()
+		buf := bytes.NewBuffer(nil)
+		logger := newStepLogger(slog.New(NewTextHandler(buf, &slog.HandlerOptions{Level: LevelWarn})), opts.stepLoggerOptions)
+		execer := wrapConn(newMockErrConn(nil), logger, opts.DriverOptions.ConnOptions).(driver.ExecerContext)
+		for range 5 {
+			if _, err := execer.ExecContext(context.Background(), "DELETE FROM users", nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if buf.Len() > 0 {
+			t.Fatalf("Unexpected log: %q", buf.String())
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package sqlslog
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RecentQueries sets the number of the recent queries kept in memory.
+// The queries are recorded when the steps which prepare or run a query finish,
+// and returned by [Factory.RecentQueries] and [Factory.DumpRecentQueries].
+// If it's zero or negative, no query is kept.
+// The default is zero.
+func RecentQueries(n int) Option {
+	return func(o *options) { o.stepLoggerOptions.recentQueries = newQueryRing(n) }
+}
+
+// QueryRecord is the record of a recent query.
+type QueryRecord struct {
+	Time     time.Time     // Time when the step started.
+	Step     Step          // Step which prepared or ran the query.
+	Query    string        // Query as logged.
+	Args     string        // Args as logged. It's empty for the steps which prepare a query.
+	Duration time.Duration // Duration of the step.
+	Error    error         // Error returned by the step.
+	IDs      []slog.Attr   // IDs such as conn_id, tx_id and stmt_id.
+}
+
+// String returns the record in a line.
+func (r QueryRecord) String() string {
+	parts := []string{r.Time.Format(time.RFC3339Nano), r.Step.String(), r.Duration.String()}
+	for _, attr := range r.IDs {
+		parts = append(parts, attr.String())
+	}
+	parts = append(parts, fmt.Sprintf("query=%q", r.Query))
+	if r.Args != "" {
+		parts = append(parts, fmt.Sprintf("args=%q", r.Args))
+	}
+	if r.Error != nil {
+		parts = append(parts, fmt.Sprintf("error=%q", r.Error.Error()))
+	}
+	return strings.Join(parts, " ")
+}
+
+// queryRing is the bounded buffer of the recent queries.
+type queryRing struct {
+	mu      sync.Mutex
+	records []QueryRecord
+	next    int
+	full    bool
+}
+
+func newQueryRing(n int) *queryRing {
+	if n <= 0 {
+		return nil
+	}
+	return &queryRing{records: make([]QueryRecord, n)}
+}
+
+func (q *queryRing) add(r QueryRecord) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.records[q.next] = r
+	q.next++
+	if q.next == len(q.records) {
+		q.next = 0
+		q.full = true
+	}
+}
+
+// list returns the records from the oldest to the newest.
+func (q *queryRing) list() []QueryRecord {
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.full {
+		return append([]QueryRecord{}, q.records[:q.next]...)
+	}
+	return append(append([]QueryRecord{}, q.records[q.next:]...), q.records[:q.next]...)
+}
+
+// recordQuery adds the step to the recent queries if it prepares or runs a query.
+func (x *stepLogger) recordQuery(step *StepOptions, t0 time.Time, d time.Duration, err error) {
+	ring := x.options.recentQueries
+	if ring == nil || x.query == nil {
+		return
+	}
+	r := QueryRecord{Time: t0, Step: step.step, Query: x.query.logged, Duration: d, Error: err, IDs: x.ids}
+	switch step.step { // nolint:exhaustive
+	case StepConnPrepare, StepConnPrepareContext:
+	default:
+		if !step.step.runsQuery() {
+			return
+		}
+		r.Args = x.argsText()
+	}
+	ring.add(r)
+}
+
+// RecentQueries returns the recent queries from the oldest to the newest.
+// See [RecentQueries] option to keep them.
+func (f *Factory) RecentQueries() []QueryRecord {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.options.stepLoggerOptions.recentQueries.list()
+}
+
+// DumpRecentQueries writes the recent queries to w in lines from the oldest to the newest.
+// It's useful in a panic handler or a SIGQUIT hook to print what the database layer was doing.
+func (f *Factory) DumpRecentQueries(w io.Writer) error {
+	for _, r := range f.RecentQueries() {
+		if _, err := fmt.Fprintln(w, r.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
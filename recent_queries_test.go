@@ -0,0 +1,89 @@
+package sqlslog
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRecentQueries(t *testing.T) {
+	t.Parallel()
+	factory := New("sqlite3", "", RecentQueries(3))
+	if len(factory.RecentQueries()) != 0 {
+		t.Fatalf("Expected no records, got %+v", factory.RecentQueries())
+	}
+
+	logger := newStepLogger(slog.New(NewTextHandler(io.Discard, nil)), factory.options.stepLoggerOptions)
+	logger = logger.withID(slog.String(ConnIDKeyDefault, "conn1"))
+	conn := wrapConn(&mockResultConn{rows: 1}, logger, factory.options.DriverOptions.ConnOptions)
+	ctx := context.Background()
+	for i := range 3 {
+		if _, err := conn.(driver.ExecerContext).ExecContext(ctx, "DELETE FROM users WHERE id = ?", []driver.NamedValue{{Ordinal: 1, Value: i}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows, err := conn.(driver.QueryerContext).QueryContext(ctx, "SELECT id FROM users", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Rows.Next is not recorded
+	if err := rows.Next(make([]driver.Value, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	records := factory.RecentQueries()
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %+v", records)
+	}
+	for i, expected := range []struct {
+		step Step
+		args string
+	}{
+		{StepConnExecContext, "[{Name: Ordinal:1 Value:1}]"},
+		{StepConnExecContext, "[{Name: Ordinal:1 Value:2}]"},
+		{StepConnQueryContext, "[]"},
+	} {
+		r := records[i]
+		if r.Step != expected.step || r.Args != expected.args || r.Error != nil || len(r.IDs) != 1 || r.Time.IsZero() {
+			t.Errorf("Unexpected record %d: %+v", i, r)
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := factory.DumpRecentQueries(buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %q", buf.String())
+	}
+	if !strings.HasSuffix(lines[2], ` conn_id=conn1 query="SELECT id FROM users" args="[]"`) ||
+		!strings.Contains(lines[2], " Conn.QueryContext ") {
+		t.Errorf("Unexpected line: %q", lines[2])
+	}
+}
+
+func TestQueryRecordString(t *testing.T) {
+	t.Parallel()
+	r := QueryRecord{Step: StepConnPrepare, Query: "SELECT 1", Error: errors.New("boom")}
+	expected := `0001-01-01T00:00:00Z Conn.Prepare 0s query="SELECT 1" error="boom"`
+	if actual := r.String(); actual != expected {
+		t.Errorf("Expected %q but got %q", expected, actual)
+	}
+}
+
+func TestRecentQueriesDisabled(t *testing.T) {
+	t.Parallel()
+	factory := New("sqlite3", "")
+	if factory.RecentQueries() != nil {
+		t.Fatal("Expected nil")
+	}
+	if newQueryRing(0) != nil {
+		t.Fatal("Expected nil ring")
+	}
+}
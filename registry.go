@@ -0,0 +1,192 @@
+package sqlslog
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DebugRegistry sets whether the open connections, transactions, statements and rows
+// are registered in the in-process registry to be listed by [DebugHandler].
+// The default is false.
+func DebugRegistry(v bool) Option {
+	return func(o *options) { o.stepLoggerOptions.registry = v }
+}
+
+const (
+	openObjectConn = "Conn"
+	openObjectTx   = "Tx"
+	openObjectStmt = "Stmt"
+	openObjectRows = "Rows"
+)
+
+// openObject is the entry of an open object in the registry.
+type openObject struct {
+	kind    string
+	ids     []slog.Attr
+	created time.Time
+	query   string
+
+	mu sync.Mutex
+	// active is true while the connection runs a query.
+	active bool
+	// tx is the transaction in progress on the connection.
+	tx *openObject
+	// statements is the number of the statements run in the transaction.
+	statements int
+}
+
+// registry is the set of the open objects.
+type registry struct {
+	mu      sync.Mutex
+	objects map[*openObject]struct{}
+}
+
+var defaultRegistry = &registry{objects: map[*openObject]struct{}{}}
+
+// register adds a new entry of the object to the registry.
+// It returns nil if the registry is disabled.
+func (x *stepLogger) register(kind, query string) *openObject {
+	if x.options == nil || !x.options.registry {
+		return nil
+	}
+	r := &openObject{kind: kind, ids: x.ids, created: time.Now(), query: query}
+	defaultRegistry.mu.Lock()
+	defer defaultRegistry.mu.Unlock()
+	defaultRegistry.objects[r] = struct{}{}
+	return r
+}
+
+// unregister removes the entry from the registry. It's safe to call unregister with nil.
+func (o *openObject) unregister() {
+	if o == nil {
+		return
+	}
+	defaultRegistry.mu.Lock()
+	defer defaultRegistry.mu.Unlock()
+	delete(defaultRegistry.objects, o)
+}
+
+// startQuery marks the connection as running the query. It's safe to call startQuery with nil.
+func (o *openObject) startQuery(query string) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.active = true
+	o.query = query
+	if o.tx != nil {
+		o.tx.mu.Lock()
+		o.tx.statements++
+		o.tx.mu.Unlock()
+	}
+}
+
+// endQuery marks the connection as idle. It's safe to call endQuery with nil.
+func (o *openObject) endQuery() {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.active = false
+}
+
+// setTx sets the transaction in progress on the connection. It's safe to call setTx with nil.
+func (o *openObject) setTx(tx *openObject) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.tx = tx
+}
+
+// withID returns a stepLogger with the ID attribute such as conn_id, tx_id and stmt_id.
+func (x *stepLogger) withID(attr slog.Attr) *stepLogger {
+	r := x.With(attr)
+	r.ids = append(append([]slog.Attr{}, x.ids...), attr)
+	return r
+}
+
+// loggedQuery returns the query to be logged or an empty string if the logger has no query.
+func (x *stepLogger) loggedQuery() string {
+	if x.query == nil {
+		return ""
+	}
+	return x.query.logged
+}
+
+// withOpenConn returns a stepLogger for the connection registered as the given entry.
+func (x *stepLogger) withOpenConn(conn *openObject) *stepLogger {
+	r := *x
+	r.conn = conn
+	return &r
+}
+
+// DebugHandler returns an HTTP handler which lists the open connections, transactions,
+// statements and rows registered by [DebugRegistry] in plain text like net/http/pprof.
+// Each line has the IDs, the age and the query of the object.
+// Connections also have the state, and transactions have the number of the statements so far.
+func DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		defaultRegistry.writeTo(w, time.Now())
+	})
+}
+
+// writeTo writes the open objects grouped by kind in the order of their creation.
+func (r *registry) writeTo(w io.Writer, now time.Time) {
+	r.mu.Lock()
+	objects := make([]*openObject, 0, len(r.objects))
+	for o := range r.objects {
+		objects = append(objects, o)
+	}
+	r.mu.Unlock()
+	sort.Slice(objects, func(i, j int) bool { return objects[i].created.Before(objects[j].created) })
+
+	for _, kind := range []string{openObjectConn, openObjectTx, openObjectStmt, openObjectRows} {
+		var lines []string
+		for _, o := range objects {
+			if o.kind == kind {
+				lines = append(lines, o.line(now))
+			}
+		}
+		fmt.Fprintf(w, "%s: %d\n", kind, len(lines))
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func (o *openObject) line(now time.Time) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	parts := make([]string, 0, len(o.ids)+4) // nolint:mnd
+	for _, attr := range o.ids {
+		parts = append(parts, attr.String())
+	}
+	parts = append(parts, "age="+now.Sub(o.created).Round(time.Millisecond).String())
+	switch o.kind {
+	case openObjectConn:
+		state := "idle"
+		if o.active {
+			state = "active"
+		}
+		parts = append(parts, "state="+state)
+	case openObjectTx:
+		parts = append(parts, fmt.Sprintf("statements=%d", o.statements))
+	}
+	if o.query != "" {
+		parts = append(parts, fmt.Sprintf("query=%q", o.query))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,89 @@
+package sqlslog
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	t.Parallel()
+
+	opts := newOptions("sqlite3", DebugRegistry(true), IDGenerator(func() string { return "registry-test" }))
+	logger := newStepLogger(slog.New(NewTextHandler(io.Discard, nil)), opts.stepLoggerOptions)
+	logger = logger.withID(slog.String(ConnIDKeyDefault, "registry-test-conn"))
+	conn := wrapConn(&mockResultConn{rows: 1}, logger, opts.DriverOptions.ConnOptions)
+
+	ctx := context.Background()
+	tx, err := conn.(driver.ConnBeginTx).BeginTx(ctx, driver.TxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := conn.(driver.ExecerContext).ExecContext(ctx, "DELETE FROM users", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows, err := conn.(driver.QueryerContext).QueryContext(ctx, "SELECT id FROM users", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt, err := conn.(driver.ConnPrepareContext).PrepareContext(ctx, "SELECT name FROM users WHERE id = ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func() string {
+		rec := httptest.NewRecorder()
+		DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/sqlslog", nil))
+		if ct := rec.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+			t.Errorf("Unexpected Content-Type: %q", ct)
+		}
+		return rec.Body.String()
+	}
+
+	out := get()
+	for _, re := range []string{
+		`(?m)^conn_id=registry-test-conn age=\S+ state=idle query="SELECT id FROM users"$`,
+		`(?m)^conn_id=registry-test-conn tx_id=registry-test age=\S+ statements=3$`,
+		`(?m)^conn_id=registry-test-conn age=\S+ query="SELECT id FROM users"$`,
+		`(?m)^conn_id=registry-test-conn stmt_id=registry-test age=\S+ query="SELECT name FROM users WHERE id = \?"$`,
+		`(?m)^Conn: \d+$`,
+		`(?m)^Rows: \d+$`,
+	} {
+		if !regexp.MustCompile(re).MatchString(out) {
+			t.Errorf("Expected %s in %q", re, out)
+		}
+	}
+
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := stmt.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if out := get(); strings.Contains(out, "registry-test") {
+		t.Errorf("Unexpected open objects in %q", out)
+	}
+}
+
+func TestDebugRegistryDisabled(t *testing.T) {
+	t.Parallel()
+	opts := newOptions("sqlite3")
+	logger := newStepLogger(slog.New(NewTextHandler(io.Discard, nil)), opts.stepLoggerOptions)
+	if entry := logger.register(openObjectConn, ""); entry != nil {
+		t.Fatalf("Expected nil but got %+v", entry)
+	}
+}
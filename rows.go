@@ -28,8 +28,13 @@ func wrapRows(original driver.Rows, logger *stepLogger, options *rowsOptions) dr
 	}
 	rw := rowsWrapper{original: original, logger: logger, options: options}
 	if rnrs, ok := original.(driver.RowsNextResultSet); ok {
-		return &rowsNextResultSetWrapper{rw, rnrs}
+		r := &rowsNextResultSetWrapper{rw, rnrs}
+		r.leak = logger.trackLeak(r, leakObjectRows)
+		r.entry = logger.register(openObjectRows, logger.loggedQuery())
+		return r
 	}
+	rw.leak = logger.trackLeak(&rw, leakObjectRows)
+	rw.entry = logger.register(openObjectRows, logger.loggedQuery())
 	return &rw
 }
 
@@ -37,13 +42,26 @@ type rowsWrapper struct {
 	original driver.Rows
 	logger   *stepLogger
 	options  *rowsOptions
+	leak     *leakRecord
+	entry    *openObject
+	// rows is the number of the rows returned by Next.
+	rows int64
 }
 
 var _ driver.Rows = (*rowsWrapper)(nil)
 
 // Close implements driver.Rows.
 func (r *rowsWrapper) Close() error {
-	return ignoreAttr(r.logger.StepWithoutContext(&r.options.Close, withNilAttr(r.original.Close)))
+	r.leak.close()
+	r.entry.unregister()
+	return ignoreAttr(r.logger.StepWithoutContext(&r.options.Close, func() (*slog.Attr, error) {
+		err := r.original.Close()
+		if !r.logger.options.semconv {
+			return nil, err
+		}
+		attr := slog.Int64(semconvReturnedRowsKey, r.rows)
+		return &attr, err
+	}))
 }
 
 // Columns implements driver.Rows.
@@ -54,7 +72,11 @@ func (r *rowsWrapper) Columns() []string {
 // Next implements driver.Rows.
 func (r *rowsWrapper) Next(dest []driver.Value) error {
 	return ignoreAttr(r.logger.StepWithoutContext(&r.options.Next, func() (*slog.Attr, error) {
-		return nil, r.original.Next(dest)
+		err := r.original.Next(dest)
+		if err == nil {
+			r.rows++
+		}
+		return nil, err
 	}))
 }
 
@@ -0,0 +1,103 @@
+package sqlslog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// SemConv sets whether the attributes are named by the OpenTelemetry semantic conventions for databases
+// without the OpenTelemetry SDK. When it's enabled,
+//
+//   - the query is logged as db.query.text instead of query.
+//   - db.operation.name is logged with the first keyword of the statement such as SELECT, REPLACE and CREATE.
+//     The keyword of the main statement is used for the queries with WITH clauses.
+//   - db.collection.name is logged when the query touches only one table.
+//   - db.system, db.name, server.address and server.port are logged as [DSNAttrs] with the OpenTelemetry names of the systems.
+//   - db.response.returned_rows is logged with the Complete event of Rows.Close,
+//     whose level is raised to [LevelInfo] to be logged by default. Give [RowsClose] after SemConv to change it.
+//   - error.type is logged with the error events. It's the error code, SQLSTATE, canceled, timeout or the type of the error.
+//   - the durations are logged in seconds as float like [DurationSeconds] regardless of [Duration].
+//     It includes the durations of the other events such as deadline_remaining, wasted and the budget report.
+//
+// The default is false.
+func SemConv(v bool) Option {
+	return func(o *options) {
+		o.stepLoggerOptions.semconv = v
+		o.stepLoggerOptions.queryOptions.semconv = v
+		if rowsClose := &o.DriverOptions.ConnOptions.RowsOptions.Close; v && rowsClose.Complete.Level < LevelInfo {
+			rowsClose.Complete.Level = LevelInfo
+		}
+	}
+}
+
+// The keys of the attributes by the OpenTelemetry semantic conventions.
+const (
+	semconvQueryTextKey    = "db.query.text"
+	semconvOperationKey    = "db.operation.name"
+	semconvCollectionKey   = "db.collection.name"
+	semconvReturnedRowsKey = "db.response.returned_rows"
+	semconvErrorTypeKey    = "error.type"
+)
+
+// semconvSystems is the values of db.system by the OpenTelemetry semantic conventions
+// for [Dialect.System] which differ from them.
+var semconvSystems = map[string]string{
+	"postgres":  "postgresql",
+	"sqlserver": "mssql",
+}
+
+func semconvSystem(system string) string {
+	if v, ok := semconvSystems[system]; ok {
+		return v
+	}
+	return system
+}
+
+// durationAttrWithKey returns the attribute of the duration other than the duration of the step.
+// It's in seconds as float with SemConv, or time.Duration otherwise.
+func (o *stepLoggerOptions) durationAttrWithKey(key string, d time.Duration) slog.Attr {
+	if o.semconv {
+		return slog.Float64(key, d.Seconds())
+	}
+	return slog.Duration(key, d)
+}
+
+// queryKey returns the key of the query attribute.
+func (o *stepLoggerOptions) queryKey() string {
+	if o.semconv {
+		return semconvQueryTextKey
+	}
+	return "query"
+}
+
+// semconvQueryAttrs returns db.operation.name and db.collection.name of the query.
+func semconvQueryAttrs(q *queryInfo) []interface{} {
+	var r []interface{}
+	if keyword := q.Keyword(); keyword != "" {
+		r = append(r, slog.String(semconvOperationKey, keyword))
+	}
+	if tables := q.Tables(); len(tables) == 1 {
+		r = append(r, slog.String(semconvCollectionKey, tables[0]))
+	}
+	return r
+}
+
+// errorTypeAttr returns error.type attribute of the error.
+func (x *stepLogger) errorTypeAttr(err error) slog.Attr {
+	info := x.errorInfo(err)
+	switch {
+	case info.Code != "":
+		return slog.String(semconvErrorTypeKey, info.Code)
+	case info.SQLState != "":
+		return slog.String(semconvErrorTypeKey, info.SQLState)
+	case errors.Is(err, context.Canceled):
+		return slog.String(semconvErrorTypeKey, "canceled")
+	case isTimeout(err):
+		return slog.String(semconvErrorTypeKey, "timeout")
+	default:
+		return slog.String(semconvErrorTypeKey, fmt.Sprintf("%T", err))
+	}
+}
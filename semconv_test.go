@@ -0,0 +1,149 @@
+package sqlslog
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSemConv(t *testing.T) {
+	t.Parallel()
+
+	newConn := func(buf *bytes.Buffer, conn driver.Conn, opts ...Option) driver.Conn {
+		f := New("postgres", "postgres://db.example.com:5432/orders", append([]Option{
+			LogWriter(buf),
+			HandlerOptions(&slog.HandlerOptions{ReplaceAttr: removeTimeAndDurationForTest}),
+			LogLevel(LevelTrace),
+		}, opts...)...)
+		logger := newStepLogger(f.stepSlogLogger(), f.options.stepLoggerOptions)
+		return wrapConn(conn, logger, f.options.DriverOptions.ConnOptions)
+	}
+
+	t.Run("query", func(t *testing.T) {
+		t.Parallel()
+		buf := bytes.NewBuffer(nil)
+		// Rows.Close is logged at the default level with SemConv.
+		conn := newConn(buf, &mockResultConn{rows: 2}, SemConv(true), LogLevel(LevelInfo))
+		rows, err := conn.(driver.QueryerContext).QueryContext(context.Background(), "SELECT id FROM users", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dest := make([]driver.Value, 1)
+		for {
+			if err := rows.Next(dest); err != nil {
+				break
+			}
+		}
+		if err := rows.Close(); err != nil {
+			t.Fatal(err)
+		}
+		expected := `level=INFO msg=Conn.QueryContext db.operation.name=SELECT db.collection.name=users db.query.text="SELECT id FROM users" args=[]` + "\n" +
+			`level=INFO msg=Rows.Close db.operation.name=SELECT db.collection.name=users db.response.returned_rows=2` + "\n"
+		if actual := buf.String(); actual != expected {
+			t.Errorf("Expected %q but got %q", expected, actual)
+		}
+	})
+
+	t.Run("operation name", func(t *testing.T) {
+		t.Parallel()
+		for query, expected := range map[string]string{
+			"REPLACE INTO kv VALUES (1, 2)":               "db.operation.name=REPLACE db.collection.name=kv ",
+			"create table orders (id integer)":            "db.operation.name=CREATE db.collection.name=orders ",
+			"WITH t AS (SELECT 1) UPDATE users SET n = 1": "db.operation.name=UPDATE db.collection.name=users ",
+			"BEGIN":               "db.operation.name=BEGIN db.query.text=BEGIN ",
+			"/* nothing */":       `msg=Conn.ExecContext db.query.text="/* nothing */" `,
+			"TRUNCATE TABLE logs": "db.operation.name=TRUNCATE db.collection.name=logs ",
+			"DELETE FROM sessions s USING users u WHERE s.uid = u.id": "db.operation.name=DELETE ",
+		} {
+			buf := bytes.NewBuffer(nil)
+			conn := newConn(buf, &mockResultConn{}, SemConv(true), LogLevel(LevelInfo))
+			if _, err := conn.(driver.ExecerContext).ExecContext(context.Background(), query, nil); err != nil {
+				t.Fatal(err)
+			}
+			if actual := buf.String(); !strings.Contains(actual, expected) {
+				t.Errorf("Expected %q in %q", expected, actual)
+			}
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		buf := bytes.NewBuffer(nil)
+		conn := newConn(buf, &mockResultConn{}, RowsClose(func(o *StepOptions) { o.SetLevel(LevelInfo) }), LogLevel(LevelInfo))
+		rows, err := conn.(driver.QueryerContext).QueryContext(context.Background(), "SELECT id FROM users", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rows.Close(); err != nil {
+			t.Fatal(err)
+		}
+		expected := `level=INFO msg=Conn.QueryContext query="SELECT id FROM users" args=[]` + "\n" +
+			`level=INFO msg=Rows.Close` + "\n"
+		if actual := buf.String(); actual != expected {
+			t.Errorf("Expected %q but got %q", expected, actual)
+		}
+	})
+
+	t.Run("db attrs", func(t *testing.T) {
+		t.Parallel()
+		o := newOptions("postgres", SemConv(true))
+		var actual []string
+		for _, a := range o.dbAttrs("postgres://db.example.com:5432/orders") {
+			actual = append(actual, a.String())
+		}
+		expected := "db.system=postgresql db.name=orders server.address=db.example.com server.port=5432"
+		if strings.Join(actual, " ") != expected {
+			t.Errorf("Expected %q but got %q", expected, actual)
+		}
+	})
+
+	t.Run("other durations", func(t *testing.T) {
+		t.Parallel()
+		o := newOptions("postgres", SemConv(true))
+		if attr := o.durationAttrWithKey(DeadlineRemainingKey, 250*time.Millisecond); attr.Value.Kind() != slog.KindFloat64 || attr.Value.Float64() != 0.25 {
+			t.Errorf("Unexpected duration: %v", attr)
+		}
+		if attr := newOptions("postgres").durationAttrWithKey(DeadlineRemainingKey, 250*time.Millisecond); attr.Value.Kind() != slog.KindDuration {
+			t.Errorf("Unexpected duration without SemConv: %v", attr)
+		}
+		for _, attr := range (BudgetReport{Duration: time.Second, Wasted: time.Second / 2, seconds: true}).Attrs() {
+			if attr.Key == budgetDurationKey && attr.Value.Float64() != 1 || attr.Key == budgetWastedKey && attr.Value.Float64() != 0.5 {
+				t.Errorf("Unexpected budget duration: %v", attr)
+			}
+		}
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		t.Parallel()
+		o := newOptions("postgres", SemConv(true), Duration(DurationMilliSeconds))
+		attr := newStepLogger(slog.Default(), o.stepLoggerOptions).durationAttr(1500 * time.Millisecond)
+		if attr.Value.Kind() != slog.KindFloat64 || attr.Value.Float64() != 1.5 {
+			t.Errorf("Unexpected duration: %v", attr)
+		}
+	})
+}
+
+func TestErrorTypeAttr(t *testing.T) {
+	t.Parallel()
+	logger := newStepLogger(slog.Default(), newOptions("postgres", SemConv(true)).stepLoggerOptions)
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{&mockPgError{Code: "23505"}, "23505"},
+		{context.Canceled, "canceled"},
+		{context.DeadlineExceeded, "timeout"},
+		{&mockMySQLError{Number: 1062, Message: "duplicate"}, "1062"},
+		{errors.New("unexpected"), "*errors.errorString"}, // nolint:err113
+	}
+	for _, tt := range tests {
+		if actual := logger.errorTypeAttr(tt.err).Value.String(); actual != tt.expected {
+			t.Errorf("%v: expected %q but got %q", tt.err, tt.expected, actual)
+		}
+	}
+}
@@ -11,6 +11,10 @@ type slogOptions struct {
 	handler     slog.Handler
 	handlerFunc func(io.Writer, *slog.HandlerOptions) slog.Handler
 	logWriter   io.Writer
+
+	// attrGroup and attrKeys are applied to the logs by sqlslog only.
+	attrGroup string
+	attrKeys  map[string]string
 }
 
 func defaultSlogOptions() *slogOptions {
@@ -0,0 +1,253 @@
+package sqlslog
+
+import "strings"
+
+// SQLSyntax is the SQL syntax which affects how the queries are tokenized
+// such as the placeholder style, the comments and the quotes.
+type SQLSyntax int
+
+const (
+	SQLSyntaxGeneric  SQLSyntax = iota // Any placeholder style is accepted.
+	SQLSyntaxPostgres                  // Placeholders are $1, $2, ... and :: is a type cast.
+	SQLSyntaxMySQL                     // Placeholders are ?, # starts a comment and "..." is a string.
+	SQLSyntaxSQLite                    // Placeholders are ?, ?NNN, :name, @name and $name.
+)
+
+type sqlTokenKind int
+
+const (
+	sqlTokenSpace sqlTokenKind = iota
+	sqlTokenComment
+	sqlTokenWord
+	sqlTokenQuotedIdent
+	sqlTokenString
+	sqlTokenNumber
+	sqlTokenPlaceholder
+	sqlTokenPunct
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+}
+
+// isWord returns true if the token is the given keyword regardless of the case.
+func (t sqlToken) isWord(word string) bool {
+	return t.kind == sqlTokenWord && strings.EqualFold(t.text, word)
+}
+
+// isLiteral returns true if the token is a string, a number or a placeholder.
+func (t sqlToken) isLiteral() bool {
+	return t.kind == sqlTokenString || t.kind == sqlTokenNumber || t.kind == sqlTokenPlaceholder
+}
+
+// tokenizeSQL splits the query into tokens.
+// It is a best-effort lexer which never fails. Unknown characters are returned as punctuations.
+func tokenizeSQL(syntax SQLSyntax, src string) []sqlToken {
+	lx := &sqlLexer{src: src, syntax: syntax}
+	var r []sqlToken
+	for lx.pos < len(lx.src) {
+		start := lx.pos
+		kind := lx.next()
+		r = append(r, sqlToken{kind: kind, text: lx.src[start:lx.pos]})
+	}
+	return r
+}
+
+type sqlLexer struct {
+	src    string
+	pos    int
+	syntax SQLSyntax
+}
+
+func (lx *sqlLexer) peek(offset int) byte {
+	if lx.pos+offset < len(lx.src) {
+		return lx.src[lx.pos+offset]
+	}
+	return 0
+}
+
+// next consumes a token and returns its kind.
+func (lx *sqlLexer) next() sqlTokenKind { // nolint:cyclop,funlen,gocognit,gocyclo
+	start, c := lx.pos, lx.src[lx.pos]
+	switch {
+	case isSQLSpace(c):
+		for lx.pos < len(lx.src) && isSQLSpace(lx.src[lx.pos]) {
+			lx.pos++
+		}
+		return sqlTokenSpace
+	case c == '-' && lx.peek(1) == '-', c == '#' && lx.syntax == SQLSyntaxMySQL:
+		lx.skipUntil("\n")
+		return sqlTokenComment
+	case c == '/' && lx.peek(1) == '*':
+		lx.pos += 2
+		lx.skipUntil("*/")
+		return sqlTokenComment
+	case c == '\'':
+		lx.quoted('\'', lx.syntax == SQLSyntaxMySQL)
+		return sqlTokenString
+	case c == '"':
+		if lx.syntax == SQLSyntaxMySQL {
+			lx.quoted('"', true)
+			return sqlTokenString
+		}
+		lx.quoted('"', false)
+		return sqlTokenQuotedIdent
+	case c == '`':
+		lx.quoted('`', false)
+		return sqlTokenQuotedIdent
+	case c == '[' && lx.syntax == SQLSyntaxSQLite:
+		lx.pos++
+		lx.skipUntil("]")
+		return sqlTokenQuotedIdent
+	case c == '$' && isSQLDigit(lx.peek(1)) && lx.syntax != SQLSyntaxMySQL:
+		lx.pos++
+		lx.skipWhile(isSQLDigit)
+		return sqlTokenPlaceholder
+	case c == '$' && lx.syntax == SQLSyntaxPostgres:
+		if tag, ok := lx.dollarQuoteTag(); ok {
+			lx.pos += len(tag)
+			lx.skipUntil(tag)
+			return sqlTokenString
+		}
+		lx.pos++
+		return sqlTokenPunct
+	case c == '?' && lx.syntax != SQLSyntaxPostgres:
+		lx.pos++
+		lx.skipWhile(isSQLDigit)
+		return sqlTokenPlaceholder
+	case c == ':' && lx.peek(1) == ':':
+		lx.pos += 2
+		return sqlTokenPunct
+	case (c == ':' || c == '@' || c == '$') && isSQLIdentStart(lx.peek(1)) && lx.namedPlaceholderAvailable(c):
+		lx.pos++
+		lx.skipWhile(isSQLIdentPart)
+		return sqlTokenPlaceholder
+	case isSQLDigit(c), c == '.' && isSQLDigit(lx.peek(1)):
+		lx.number()
+		return sqlTokenNumber
+	case isSQLIdentStart(c):
+		lx.skipWhile(isSQLIdentPart)
+		// Prefixed strings such as E'...', N'...', X'...' and B'...'
+		if lx.pos-start == 1 && strings.IndexByte("EeNnXxBb", c) >= 0 && lx.peek(0) == '\'' {
+			lx.quoted('\'', lx.syntax == SQLSyntaxMySQL)
+			return sqlTokenString
+		}
+		return sqlTokenWord
+	case isSQLOperator(c):
+		lx.pos++
+		for lx.pos < len(lx.src) && isSQLOperator(lx.src[lx.pos]) && !lx.startsComment() {
+			lx.pos++
+		}
+		return sqlTokenPunct
+	default:
+		lx.pos++
+		return sqlTokenPunct
+	}
+}
+
+// namedPlaceholderAvailable returns true if :name, @name or $name is a placeholder in the syntax.
+func (lx *sqlLexer) namedPlaceholderAvailable(c byte) bool {
+	switch lx.syntax {
+	case SQLSyntaxPostgres, SQLSyntaxMySQL:
+		return false
+	case SQLSyntaxSQLite:
+		return true
+	default:
+		return c != '$'
+	}
+}
+
+func (lx *sqlLexer) startsComment() bool {
+	c, n := lx.src[lx.pos], lx.peek(1)
+	return c == '-' && n == '-' || c == '/' && n == '*'
+}
+
+// quoted consumes a quoted token which starts at the current position.
+// The quote character in the token is escaped by doubling it,
+// or by a backslash if backslash is true.
+func (lx *sqlLexer) quoted(quote byte, backslash bool) {
+	lx.pos++
+	for lx.pos < len(lx.src) {
+		c := lx.src[lx.pos]
+		switch {
+		case backslash && c == '\\':
+			lx.pos += 2
+		case c == quote && lx.peek(1) == quote:
+			lx.pos += 2
+		case c == quote:
+			lx.pos++
+			return
+		default:
+			lx.pos++
+		}
+	}
+	if lx.pos > len(lx.src) {
+		lx.pos = len(lx.src)
+	}
+}
+
+// dollarQuoteTag returns the tag of dollar-quoted string like $$ or $tag$ at the current position.
+func (lx *sqlLexer) dollarQuoteTag() (string, bool) {
+	i := lx.pos + 1
+	for i < len(lx.src) && isSQLIdentPart(lx.src[i]) && lx.src[i] != '$' {
+		i++
+	}
+	if i < len(lx.src) && lx.src[i] == '$' {
+		return lx.src[lx.pos : i+1], true
+	}
+	return "", false
+}
+
+func (lx *sqlLexer) number() {
+	if lx.src[lx.pos] == '0' && (lx.peek(1) == 'x' || lx.peek(1) == 'X') {
+		lx.pos += 2
+		lx.skipWhile(isSQLIdentPart)
+		return
+	}
+	lx.skipWhile(isSQLDigit)
+	if lx.peek(0) == '.' {
+		lx.pos++
+		lx.skipWhile(isSQLDigit)
+	}
+	if c := lx.peek(0); c == 'e' || c == 'E' {
+		if isSQLDigit(lx.peek(1)) {
+			lx.pos++
+		} else if (lx.peek(1) == '+' || lx.peek(1) == '-') && isSQLDigit(lx.peek(2)) {
+			lx.pos += 2
+		}
+		lx.skipWhile(isSQLDigit)
+	}
+}
+
+func (lx *sqlLexer) skipUntil(terminator string) {
+	if i := strings.Index(lx.src[lx.pos:], terminator); i >= 0 {
+		lx.pos += i + len(terminator)
+	} else {
+		lx.pos = len(lx.src)
+	}
+}
+
+func (lx *sqlLexer) skipWhile(f func(byte) bool) {
+	for lx.pos < len(lx.src) && f(lx.src[lx.pos]) {
+		lx.pos++
+	}
+}
+
+func isSQLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isSQLDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func isSQLIdentStart(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || c >= 0x80
+}
+
+func isSQLIdentPart(c byte) bool {
+	return isSQLIdentStart(c) || isSQLDigit(c) || c == '$'
+}
+
+func isSQLOperator(c byte) bool {
+	return strings.IndexByte("<>=!|&+-*/%^~", c) >= 0
+}
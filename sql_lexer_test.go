@@ -0,0 +1,102 @@
+package sqlslog
+
+import (
+	"testing"
+)
+
+func TestTokenizeSQL(t *testing.T) {
+	t.Parallel()
+	type tok = sqlToken
+	tests := []struct {
+		name     string
+		syntax   SQLSyntax
+		src      string
+		expected []tok
+	}{
+		{
+			name:   "comments and strings",
+			syntax: SQLSyntaxGeneric,
+			src:    "-- c\nSELECT 'it''s' /* x */",
+			expected: []tok{
+				{sqlTokenComment, "-- c\n"},
+				{sqlTokenWord, "SELECT"},
+				{sqlTokenSpace, " "},
+				{sqlTokenString, "'it''s'"},
+				{sqlTokenSpace, " "},
+				{sqlTokenComment, "/* x */"},
+			},
+		},
+		{
+			name:   "postgres",
+			syntax: SQLSyntaxPostgres,
+			src:    "a::int=$1 ? $$x$$",
+			expected: []tok{
+				{sqlTokenWord, "a"},
+				{sqlTokenPunct, "::"},
+				{sqlTokenWord, "int"},
+				{sqlTokenPunct, "="},
+				{sqlTokenPlaceholder, "$1"},
+				{sqlTokenSpace, " "},
+				{sqlTokenPunct, "?"},
+				{sqlTokenSpace, " "},
+				{sqlTokenString, "$$x$$"},
+			},
+		},
+		{
+			name:   "mysql",
+			syntax: SQLSyntaxMySQL,
+			src:    "`t`.\"a\\\"b\"<=? # c",
+			expected: []tok{
+				{sqlTokenQuotedIdent, "`t`"},
+				{sqlTokenPunct, "."},
+				{sqlTokenString, "\"a\\\"b\""},
+				{sqlTokenPunct, "<="},
+				{sqlTokenPlaceholder, "?"},
+				{sqlTokenSpace, " "},
+				{sqlTokenComment, "# c"},
+			},
+		},
+		{
+			name:   "sqlite",
+			syntax: SQLSyntaxSQLite,
+			src:    "[t] ?12 :a @b $c 1.5e-3 X'ff'",
+			expected: []tok{
+				{sqlTokenQuotedIdent, "[t]"},
+				{sqlTokenSpace, " "},
+				{sqlTokenPlaceholder, "?12"},
+				{sqlTokenSpace, " "},
+				{sqlTokenPlaceholder, ":a"},
+				{sqlTokenSpace, " "},
+				{sqlTokenPlaceholder, "@b"},
+				{sqlTokenSpace, " "},
+				{sqlTokenPlaceholder, "$c"},
+				{sqlTokenSpace, " "},
+				{sqlTokenNumber, "1.5e-3"},
+				{sqlTokenSpace, " "},
+				{sqlTokenString, "X'ff'"},
+			},
+		},
+		{
+			name:   "unterminated",
+			syntax: SQLSyntaxGeneric,
+			src:    "'abc",
+			expected: []tok{
+				{sqlTokenString, "'abc"},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			actual := tokenizeSQL(tc.syntax, tc.src)
+			if len(actual) != len(tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+			for i := range actual {
+				if actual[i] != tc.expected[i] {
+					t.Errorf("[%d] expected %v, got %v", i, tc.expected[i], actual[i])
+				}
+			}
+		})
+	}
+}
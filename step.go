@@ -10,6 +10,7 @@ const (
 	StepConnBegin          Step = "Conn.Begin"
 	StepConnBeginTx        Step = "Conn.BeginTx"
 	StepConnClose          Step = "Conn.Close"
+	StepConnIsValid        Step = "Conn.IsValid"
 	StepConnPrepare        Step = "Conn.Prepare"
 	StepConnPrepareContext Step = "Conn.PrepareContext"
 	StepConnResetSession   Step = "Conn.ResetSession"
@@ -37,3 +38,26 @@ const (
 	StepTxCommit   Step = "Tx.Commit"
 	StepTxRollback Step = "Tx.Rollback"
 )
+
+// steps is all the steps.
+var steps = []Step{
+	StepConnBegin, StepConnBeginTx, StepConnClose, StepConnIsValid, StepConnPrepare, StepConnPrepareContext,
+	StepConnResetSession, StepConnPing, StepConnExecContext, StepConnQueryContext,
+	StepConnectorConnect,
+	StepDriverOpen, StepDriverOpenConnector,
+	StepSqlslogOpen,
+	StepRowsClose, StepRowsNext, StepRowsNextResultSet,
+	StepStmtClose, StepStmtExec, StepStmtQuery, StepStmtExecContext, StepStmtQueryContext,
+	StepTxCommit, StepTxRollback,
+}
+
+// runsQuery returns true if the step sends a query to the database and gets its result.
+func (s Step) runsQuery() bool {
+	switch s { // nolint:exhaustive
+	case StepConnExecContext, StepConnQueryContext,
+		StepStmtExec, StepStmtQuery, StepStmtExecContext, StepStmtQueryContext:
+		return true
+	default:
+		return false
+	}
+}
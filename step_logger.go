@@ -2,6 +2,9 @@ package sqlslog
 
 import (
 	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
 	"log/slog"
 	"time"
 )
@@ -9,43 +12,194 @@ import (
 type stepLoggerOptions struct {
 	durationKey  string
 	durationType DurationType
+	tracing      bool
+	queryOptions queryOptions
+
+	nPlusOneThreshold       int
+	duplicateQueryThreshold int
+
+	explain explainOptions
+	leak    leakOptions
+
+	registry      bool
+	recentQueries *queryRing
+
+	dbStatsInterval time.Duration
+
+	badConnLevel Level
+
+	errorEnrichment bool
+	errorExtractors []ErrorExtractor
+
+	dialect *Dialect
+
+	argsMode ArgsMode
+
+	semconv bool
+
+	sampleRate float64
+	// live holds the options which can be changed at runtime. It's nil for the options built without newOptions.
+	live *liveOptions
 }
 
 func defaultStepLoggerOptions() stepLoggerOptions {
 	return stepLoggerOptions{
-		durationKey:  DurationKeyDefault,
-		durationType: DurationNanoSeconds,
+		durationKey:             DurationKeyDefault,
+		durationType:            DurationNanoSeconds,
+		queryOptions:            defaultQueryOptions(),
+		nPlusOneThreshold:       NPlusOneThresholdDefault,
+		duplicateQueryThreshold: DuplicateQueryThresholdDefault,
+		explain:                 defaultExplainOptions(),
+		leak:                    defaultLeakOptions(),
+		badConnLevel:            BadConnLevelDefault,
+		sampleRate:              SampleRateDefault,
 	}
 }
 
 type stepLogger struct {
 	*slog.Logger
 	durationAttr func(d time.Duration) slog.Attr
+	options      *stepLoggerOptions
+
+	// query is the query which the steps of this logger run.
+	// It is nil for the steps which don't run any query.
+	query *queryInfo
+	// queryLogged is true if the query is in the log attributes of this logger.
+	queryLogged bool
+
+	// args is the args of the query which the steps of this logger run.
+	// It is either []driver.Value or []driver.NamedValue.
+	args interface{}
+
+	// ctx is the context for the steps without context such as Rows.Next and Tx.Commit.
+	// It is the context which the rows or the transaction are created under.
+	ctx context.Context //nolint:containedctx
+
+	// connector is the original connector to explain the queries on a separate connection.
+	connector driver.Connector
+	explainer *explainer
+
+	// ids is the ID attributes such as conn_id, tx_id and stmt_id added to this logger.
+	ids []slog.Attr
+	// conn is the registry entry of the connection. It is nil if the registry is disabled.
+	conn *openObject
 }
 
 func newStepLogger(logger *slog.Logger, opts stepLoggerOptions) *stepLogger {
+	durationType := opts.durationType
+	if opts.semconv {
+		durationType = DurationSeconds
+	}
 	return &stepLogger{
 		Logger:       logger,
-		durationAttr: durationAttrFunc(opts.durationKey, opts.durationType),
+		durationAttr: durationAttrFunc(opts.durationKey, durationType),
+		options:      &opts,
+		explainer:    newExplainer(),
 	}
 }
 
 func (x *stepLogger) With(kv ...interface{}) *stepLogger {
-	return &stepLogger{
-		Logger:       x.Logger.With(kv...),
-		durationAttr: x.durationAttr,
+	r := *x
+	r.Logger = x.Logger.With(kv...)
+	return &r
+}
+
+// withQuery returns a stepLogger for the steps which run the given query.
+// It doesn't add the query itself to the log attributes but the attributes derived from the query.
+func (x *stepLogger) withQuery(query string) *stepLogger {
+	r := *x
+	r.query = newQueryInfo(query, &x.options.queryOptions)
+	if attrs := x.options.queryOptions.attrs(r.query); len(attrs) > 0 {
+		r.Logger = x.Logger.With(attrs...)
+	}
+	return &r
+}
+
+// withLoggedQuery returns a stepLogger which adds the query to the log attributes.
+func (x *stepLogger) withLoggedQuery() *stepLogger {
+	r := x.With(slog.String(x.options.queryKey(), x.query.logged))
+	r.queryLogged = true
+	return r
+}
+
+// queryAttrs returns the query attribute for the events which are not for a step
+// unless the logger already has it.
+func (x *stepLogger) queryAttrs() []interface{} {
+	if x.queryLogged {
+		return nil
+	}
+	return []interface{}{slog.String(x.options.queryKey(), x.query.logged)}
+}
+
+// withArgs returns a stepLogger for the steps which run the query with the given args.
+// It adds the args to the log attributes.
+func (x *stepLogger) withArgs(args interface{}) *stepLogger {
+	var r stepLogger
+	if x.options != nil && x.options.argsMode == ArgsOmitted {
+		r = *x
+	} else {
+		r = *x.With(slog.String("args", x.formatArgs(args)))
+	}
+	r.args = args
+	return &r
+}
+
+// formatArgs returns the args as logged.
+func (x *stepLogger) formatArgs(args interface{}) string {
+	if x.options != nil && x.options.argsMode == ArgsRedacted {
+		args = redactArgs(args)
 	}
+	return fmt.Sprintf("%+v", args)
+}
+
+// withContext returns a stepLogger whose steps without context run under the given context.
+func (x *stepLogger) withContext(ctx context.Context) *stepLogger {
+	r := *x
+	r.ctx = ctx
+	return &r
 }
 
 func (x *stepLogger) StepWithoutContext(step *StepOptions, fn func() (*slog.Attr, error)) (*slog.Attr, error) {
-	return x.Step(context.Background(), step, fn)
+	ctx := x.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return x.Step(ctx, step, fn)
 }
 
 func (x *stepLogger) Step(ctx context.Context, step *StepOptions, fn func() (*slog.Attr, error)) (*slog.Attr, error) {
-	x.Log(ctx, slog.Level(step.Start.Level), step.Start.Msg)
+	step = step.current()
+	lc := loggingContextFrom(ctx)
+	startLevel, completeLevel := lc.levels(step.levels(x.query))
+	lg := x
+	if args := lc.args(); len(args) > 0 {
+		lg = lg.With(args...)
+	}
+	opID := x.opID(step)
+	if opID != "" {
+		lg = lg.With(slog.String(x.options.explain.opIDKey, opID))
+	}
+	sampled := x.options.sampled()
+	if !lc.suppressed() && sampled {
+		lg.Log(ctx, slog.Level(startLevel), step.Start.Msg)
+	}
+	runsQuery := x.query != nil && step.step.runsQuery()
+	if runsQuery {
+		x.conn.startQuery(x.query.logged)
+	}
+	deadline, hasDeadline := ctx.Deadline()
 	t0 := time.Now()
-	attr, err := fn()
-	lg := x.With(x.durationAttr(time.Since(t0)))
+	var remaining *time.Duration
+	if hasDeadline {
+		v := deadline.Sub(t0)
+		remaining = &v
+	}
+	attr, err := x.invoke(ctx, step, fn)
+	d := time.Since(t0)
+	if runsQuery {
+		x.conn.endQuery()
+	}
+	lg = lg.With(x.durationAttr(d))
 	var complete bool
 	if step.ErrorHandler != nil {
 		var attrs []slog.Attr
@@ -62,15 +216,44 @@ func (x *stepLogger) Step(ctx context.Context, step *StepOptions, fn func() (*sl
 	}
 	switch {
 	case !complete:
-		lg.Log(ctx, slog.Level(step.Error.Level), step.Error.Msg, slog.Any("error", err))
+		x.logFailure(ctx, lg, step, err, remaining)
+	case lc.suppressed(), !sampled:
 	case attr != nil:
-		lg.Log(ctx, slog.Level(step.Complete.Level), step.Complete.Msg, *attr)
+		lg.Log(ctx, slog.Level(completeLevel), step.Complete.Msg, *attr)
 	default:
-		lg.Log(ctx, slog.Level(step.Complete.Level), step.Complete.Msg)
+		lg.Log(ctx, slog.Level(completeLevel), step.Complete.Msg)
 	}
+	x.trackStep(ctx, step, d, err)
+	x.explainSlowQuery(step.step, opID, d, err)
+	x.recordQuery(step, t0, d, err)
 	return attr, err
 }
 
+// logFailure logs the Canceled, Timeout or Error event of the step which fails with err.
+// remaining is the remaining time until the deadline of the context at the start of the step.
+func (x *stepLogger) logFailure(ctx context.Context, lg *stepLogger, step *StepOptions, err error, remaining *time.Duration) {
+	attrs := []interface{}{slog.Any("error", err)}
+	event := step.Error
+	canceled := errors.Is(err, context.Canceled)
+	timeout := !canceled && isTimeout(err)
+	switch {
+	case canceled:
+		event = step.Canceled
+	case timeout:
+		event = step.Timeout
+	case errors.Is(err, driver.ErrBadConn):
+		event.Level = x.options.badConnLevel
+		attrs = append(attrs, slog.Bool(BadConnKey, true))
+	}
+	if remaining != nil && (canceled || timeout) {
+		attrs = append(attrs, x.options.durationAttrWithKey(DeadlineRemainingKey, *remaining))
+	}
+	if x.options.semconv {
+		attrs = append(attrs, x.errorTypeAttr(err))
+	}
+	lg.Log(ctx, slog.Level(event.Level), event.Msg, append(attrs, x.errorAttrs(err)...)...)
+}
+
 func durationAttrFunc(key string, dt DurationType) func(d time.Duration) slog.Attr {
 	switch dt {
 	case DurationNanoSeconds:
@@ -83,6 +266,8 @@ func durationAttrFunc(key string, dt DurationType) func(d time.Duration) slog.At
 		return func(d time.Duration) slog.Attr { return slog.Duration(key, d) }
 	case DurationString:
 		return func(d time.Duration) slog.Attr { return slog.String(key, d.String()) }
+	case DurationSeconds:
+		return func(d time.Duration) slog.Attr { return slog.Float64(key, d.Seconds()) }
 	default:
 		return func(d time.Duration) slog.Attr { return slog.Int64(key, d.Nanoseconds()) }
 	}
@@ -1,7 +1,13 @@
 package sqlslog
 
 import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
 	"log/slog"
+	"regexp"
 	"testing"
 	"time"
 )
@@ -93,3 +99,88 @@ func TestStepLoggerDurationAttr(t *testing.T) {
 		})
 	}
 }
+
+func removeTimeAndDurationForTest(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == DurationKeyDefault) {
+		return slog.Attr{}
+	}
+	return a
+}
+
+func TestStepCanceledAndTimeout(t *testing.T) {
+	t.Parallel()
+
+	newCtxWithDeadline := func() context.Context {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		t.Cleanup(cancel)
+		return ctx
+	}
+
+	for _, tc := range []struct {
+		name     string
+		ctx      context.Context //nolint:containedctx
+		err      error
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "canceled",
+			ctx:      context.Background(),
+			err:      context.Canceled,
+			expected: `^level=INFO msg="Conn.ExecContext Canceled" query="DELETE FROM users" args=\[\] error="context canceled"\n$`,
+		},
+		{
+			name:     "canceled with deadline",
+			ctx:      newCtxWithDeadline(),
+			err:      fmt.Errorf("exec: %w", context.Canceled),
+			expected: `^level=INFO msg="Conn.ExecContext Canceled" query="DELETE FROM users" args=\[\] error="exec: context canceled" deadline_remaining=59m59\.\d+s\n$`,
+		},
+		{
+			name:     "deadline exceeded",
+			ctx:      context.Background(),
+			err:      context.DeadlineExceeded,
+			expected: `^level=WARN msg="Conn.ExecContext Timeout" query="DELETE FROM users" args=\[\] error="context deadline exceeded"\n$`,
+		},
+		{
+			name:     "driver timeout",
+			ctx:      context.Background(),
+			err:      mockTimeoutError{},
+			expected: `^level=WARN msg="Conn.ExecContext Timeout" query="DELETE FROM users" args=\[\] error="i/o timeout"\n$`,
+		},
+		{
+			name: "custom levels",
+			ctx:  context.Background(),
+			err:  context.Canceled,
+			opts: []Option{ConnExecContext(func(o *StepOptions) {
+				o.Canceled = EventOptions{Msg: "Exec canceled", Level: LevelDebug}
+			})},
+			expected: `^level=DEBUG msg="Exec canceled" query="DELETE FROM users" args=\[\] error="context canceled"\n$`,
+		},
+		{
+			name:     "error",
+			ctx:      context.Background(),
+			err:      errors.New("unexpected error"),
+			expected: `^level=ERROR msg="Conn.ExecContext Error" query="DELETE FROM users" args=\[\] error="unexpected error"\n$`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			buf := bytes.NewBuffer(nil)
+			o := newDefaultOptions("sqlite3", StepEventMsgWithEventName)
+			for _, opt := range tc.opts {
+				opt(o)
+			}
+			handler := NewTextHandler(buf, &slog.HandlerOptions{Level: LevelDebug, ReplaceAttr: removeTimeAndDurationForTest})
+			logger := newStepLogger(slog.New(handler), o.stepLoggerOptions)
+			conn := wrapConn(newMockErrConn(tc.err), logger, o.DriverOptions.ConnOptions)
+			if _, err := conn.(driver.ExecerContext).ExecContext(tc.ctx, "DELETE FROM users", nil); !errors.Is(err, tc.err) {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			// Skip Start event
+			out := regexp.MustCompile(`(?m)^.* Start.*\n`).ReplaceAllString(buf.String(), "")
+			if !regexp.MustCompile(tc.expected).MatchString(out) {
+				t.Errorf("Expected %s but got %q", tc.expected, out)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package sqlslog
 
-import "log/slog"
+import (
+	"log/slog"
+	"strings"
+	"unicode"
+)
 
 type EventOptions struct {
 	Msg   string
@@ -20,25 +24,95 @@ func StepEventMsgWithoutEventName(step Step, _ Event) string {
 	return step.String()
 }
 
+// StepEventMsgDotted returns the dotted lowercase step log message with "sql" prefix
+// such as "sql.conn.query_context.complete" for Conn.QueryContext and "sql.open.start" for Open.
+func StepEventMsgDotted(step Step, event Event) string {
+	return "sql." + snakeCase(step.String()) + "." + strings.ToLower(event.String())
+}
+
+// StepEventMsgLowercase returns the lowercase step log message with the event name
+// such as "conn.query_context complete".
+func StepEventMsgLowercase(step Step, event Event) string {
+	return snakeCase(step.String()) + " " + strings.ToLower(event.String())
+}
+
+// snakeCase returns the lowercase string with underscores between words such as "conn.query_context".
+func snakeCase(s string) string {
+	var b strings.Builder
+	prev := rune(0)
+	for _, c := range s {
+		if unicode.IsUpper(c) && unicode.IsLower(prev) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToLower(c))
+		prev = c
+	}
+	return b.String()
+}
+
 // StepOptions is an struct that expresses the options for the step.
 type StepOptions struct {
 	Start    EventOptions
 	Error    EventOptions
 	Complete EventOptions
 
+	// Canceled is the options for the event when the step ends with context.Canceled
+	// such as a client disconnection. It's used instead of Error.
+	Canceled EventOptions
+	// Timeout is the options for the event when the step ends with context.DeadlineExceeded
+	// or an error which has Timeout() method returning true. It's used instead of Error.
+	Timeout EventOptions
+
 	// ErrorHandler is the function to handle the error.
 	// When the error should not be logged as an error but as complete, it should return true.
 	// It can also add attributes to the log.
 	ErrorHandler func(error) (bool, []slog.Attr)
+
+	// OperationLevels is the levels for the operations of the query which the step runs.
+	// If the operation is found, the levels of Start and Complete events are replaced
+	// in the same way as SetLevel.
+	OperationLevels map[Operation]Level
+
+	step Step
+	// live holds the options updated at runtime. It's shared by the copies of the options.
+	live *liveStepOptions
 }
 
 const defaultSlogLevelDiff = 4
 
+const (
+	CanceledLevelDefault = LevelInfo // Default level of Canceled events.
+	TimeoutLevelDefault  = LevelWarn // Default level of Timeout events.
+
+	// DeadlineRemainingKey is the key for the remaining time until the deadline of the context
+	// at the start of the step. It's added to Canceled and Timeout events if the context has a deadline.
+	DeadlineRemainingKey = "deadline_remaining"
+)
+
 func (o *StepOptions) SetLevel(lv Level) {
 	o.Start.Level = lv - defaultSlogLevelDiff
 	o.Complete.Level = lv
 }
 
+// SetOperationLevel sets the level for the given operation of the query which the step runs.
+// The level of Start event is lower than lv by 4 and the level of Complete event is lv, like SetLevel.
+func (o *StepOptions) SetOperationLevel(op Operation, lv Level) {
+	if o.OperationLevels == nil {
+		o.OperationLevels = map[Operation]Level{}
+	}
+	o.OperationLevels[op] = lv
+}
+
+// levels returns the levels of Start and Complete events for the given query.
+func (o *StepOptions) levels(q *queryInfo) (Level, Level) {
+	if q != nil && len(o.OperationLevels) > 0 {
+		if lv, ok := o.OperationLevels[q.Operation()]; ok {
+			return lv - defaultSlogLevelDiff, lv
+		}
+	}
+	return o.Start.Level, o.Complete.Level
+}
+
 func (o *StepOptions) compare(other *StepOptions) bool {
 	return o.Start.Level == other.Start.Level &&
 		o.Error.Level == other.Error.Level &&
@@ -50,6 +124,10 @@ func newStepOptions(f StepEventMsgBuilder, step Step, startLevel, errorLevel, co
 		Start:    EventOptions{Msg: f(step, EventStart), Level: startLevel},
 		Error:    EventOptions{Msg: f(step, EventError), Level: errorLevel},
 		Complete: EventOptions{Msg: f(step, EventComplete), Level: completeLevel},
+		Canceled: EventOptions{Msg: f(step, EventCanceled), Level: CanceledLevelDefault},
+		Timeout:  EventOptions{Msg: f(step, EventTimeout), Level: TimeoutLevelDefault},
+		step:     step,
+		live:     &liveStepOptions{},
 	}
 }
 
@@ -77,3 +77,25 @@ func TestDefaultStepOptions(t *testing.T) {
 		}
 	})
 }
+
+func TestStepEventMsgBuilders(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		builder StepEventMsgBuilder
+		step    Step
+		event   Event
+		want    string
+	}{
+		{StepEventMsgWithEventName, StepConnQueryContext, EventComplete, "Conn.QueryContext Complete"},
+		{StepEventMsgWithoutEventName, StepConnQueryContext, EventComplete, "Conn.QueryContext"},
+		{StepEventMsgDotted, StepConnQueryContext, EventComplete, "sql.conn.query_context.complete"},
+		{StepEventMsgDotted, StepRowsNextResultSet, EventError, "sql.rows.next_result_set.error"},
+		{StepEventMsgDotted, StepSqlslogOpen, EventStart, "sql.open.start"},
+		{StepEventMsgLowercase, StepConnBeginTx, EventCanceled, "conn.begin_tx canceled"},
+	}
+	for _, tt := range tests {
+		if got := tt.builder(tt.step, tt.event); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
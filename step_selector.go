@@ -0,0 +1,51 @@
+package sqlslog
+
+import "path"
+
+// StepCategory is the category of steps which can be selected by [Steps].
+type StepCategory string
+
+const (
+	StepCategoryRead        StepCategory = "read"        // The steps which read rows such as Conn.QueryContext and Rows.Next.
+	StepCategoryWrite       StepCategory = "write"       // The steps which execute queries without rows such as Conn.ExecContext.
+	StepCategoryLifecycle   StepCategory = "lifecycle"   // The steps which open, prepare, check or close connections, statements and rows.
+	StepCategoryTransaction StepCategory = "transaction" // The steps which begin, commit or roll back transactions.
+)
+
+// Category returns the category of the step.
+func (s Step) Category() StepCategory {
+	switch s { // nolint:exhaustive
+	case StepConnQueryContext, StepStmtQuery, StepStmtQueryContext, StepRowsNext, StepRowsNextResultSet:
+		return StepCategoryRead
+	case StepConnExecContext, StepStmtExec, StepStmtExecContext:
+		return StepCategoryWrite
+	case StepConnBegin, StepConnBeginTx, StepTxCommit, StepTxRollback:
+		return StepCategoryTransaction
+	default:
+		return StepCategoryLifecycle
+	}
+}
+
+// Match returns true if the step matches the pattern.
+// The pattern is either a category such as "lifecycle" or a glob of [path.Match] such as "Rows.*" and "*.Close".
+// An invalid glob matches no step.
+func (s Step) Match(pattern string) bool {
+	if StepCategory(pattern) == s.Category() {
+		return true
+	}
+	matched, err := path.Match(pattern, string(s))
+	return err == nil && matched
+}
+
+// Steps calls f with the options of every step which matches the pattern. See [Step.Match] for the pattern.
+// For example, Steps("lifecycle", func(_ Step, o *StepOptions) { o.SetLevel(LevelVerbose) }) silences
+// all the lifecycle events with the default log level.
+func Steps(pattern string, f func(Step, *StepOptions)) Option {
+	return func(o *options) {
+		for _, s := range o.stepOptions() {
+			if s.step.Match(pattern) {
+				f(s.step, s)
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package sqlslog
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestStepMatch(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		pattern  string
+		expected []Step
+	}{
+		{"Rows.*", []Step{StepRowsClose, StepRowsNext, StepRowsNextResultSet}},
+		{"*.Close", []Step{StepConnClose, StepRowsClose, StepStmtClose}},
+		{"Stmt.*Context", []Step{StepStmtExecContext, StepStmtQueryContext}},
+		{"Tx.Commit", []Step{StepTxCommit}},
+		{"read", []Step{StepConnQueryContext, StepRowsNext, StepRowsNextResultSet, StepStmtQuery, StepStmtQueryContext}},
+		{"write", []Step{StepConnExecContext, StepStmtExec, StepStmtExecContext}},
+		{"transaction", []Step{StepConnBegin, StepConnBeginTx, StepTxCommit, StepTxRollback}},
+		{"lifecycle", []Step{
+			StepConnClose, StepConnIsValid, StepConnPing, StepConnPrepare, StepConnPrepareContext, StepConnResetSession,
+			StepConnectorConnect, StepDriverOpen, StepDriverOpenConnector, StepSqlslogOpen, StepRowsClose, StepStmtClose,
+		}},
+		{"[", nil},
+		{"unknown", nil},
+	} {
+		var actual []Step
+		for _, s := range steps {
+			if s.Match(tc.pattern) {
+				actual = append(actual, s)
+			}
+		}
+		sort.Slice(actual, func(i, j int) bool { return actual[i] < actual[j] })
+		sort.Slice(tc.expected, func(i, j int) bool { return tc.expected[i] < tc.expected[j] })
+		if len(actual) != len(tc.expected) {
+			t.Errorf("%s: expected %v but got %v", tc.pattern, tc.expected, actual)
+			continue
+		}
+		for i := range actual {
+			if actual[i] != tc.expected[i] {
+				t.Errorf("%s: expected %v but got %v", tc.pattern, tc.expected, actual)
+				break
+			}
+		}
+	}
+}
+
+func TestSteps(t *testing.T) {
+	t.Parallel()
+	var selected []Step
+	o := newOptions("sqlite3",
+		Steps("lifecycle", func(_ Step, o *StepOptions) { o.SetLevel(LevelVerbose) }),
+		Steps("Rows.*", func(step Step, _ *StepOptions) { selected = append(selected, step) }),
+	)
+	for _, s := range o.stepOptions() {
+		if s.step.Category() == StepCategoryLifecycle && s.Complete.Level != LevelVerbose {
+			t.Errorf("Unexpected level of %s: %v", s.step, s.Complete.Level)
+		}
+		if s.step.Category() != StepCategoryLifecycle && s.Complete.Level == LevelVerbose {
+			t.Errorf("Unexpected level of %s: %v", s.step, s.Complete.Level)
+		}
+	}
+	if len(selected) != 3 {
+		t.Errorf("Unexpected steps: %v", selected)
+	}
+}
@@ -3,7 +3,6 @@ package sqlslog
 import (
 	"context"
 	"database/sql/driver"
-	"fmt"
 	"log/slog"
 )
 
@@ -43,11 +42,16 @@ func wrapStmt(original driver.Stmt, logger *stepLogger, options *stmtOptions) dr
 			stmtQueryContextWrapperImpl: stmtQueryContextWrapperImpl{original: stmtQuery, logger: logger, options: options},
 		}
 		if nvc, ok := original.(driver.NamedValueChecker); ok {
-			return &stmtContextNvcWrapper{
+			r := &stmtContextNvcWrapper{
 				stmtContextWrapper: *stmtCtxW,
 				NamedValueChecker:  nvc,
 			}
+			r.leak = logger.trackLeak(r, leakObjectStmt)
+			r.entry = logger.register(openObjectStmt, logger.loggedQuery())
+			return r
 		}
+		stmtCtxW.leak = logger.trackLeak(stmtCtxW, leakObjectStmt)
+		stmtCtxW.entry = logger.register(openObjectStmt, logger.loggedQuery())
 		return stmtCtxW
 	}
 	// Commented out because the original implementation does not have this check.
@@ -70,6 +74,8 @@ func wrapStmt(original driver.Stmt, logger *stepLogger, options *stmtOptions) dr
 	// 		NamedValueChecker: nvc,
 	// 	}
 	// }
+	stmtWrapper.leak = logger.trackLeak(&stmtWrapper, leakObjectStmt)
+	stmtWrapper.entry = logger.register(openObjectStmt, logger.loggedQuery())
 	return &stmtWrapper
 }
 
@@ -77,18 +83,22 @@ type stmtWrapper struct {
 	original driver.Stmt
 	logger   *stepLogger
 	options  *stmtOptions
+	leak     *leakRecord
+	entry    *openObject
 }
 
 var _ driver.Stmt = (*stmtWrapper)(nil)
 
 // Close implements driver.Stmt.
 func (s *stmtWrapper) Close() error {
+	s.leak.close()
+	s.entry.unregister()
 	return ignoreAttr(s.logger.StepWithoutContext(&s.options.Close, withNilAttr(s.original.Close)))
 }
 
 // Exec implements driver.Stmt.
 func (s *stmtWrapper) Exec(args []driver.Value) (driver.Result, error) {
-	lg := s.logger.With(slog.String("args", fmt.Sprintf("%+v", args)))
+	lg := s.logger.withArgs(args)
 	var result driver.Result
 	err := ignoreAttr(lg.StepWithoutContext(&s.options.Exec, func() (*slog.Attr, error) {
 		var err error
@@ -108,7 +118,7 @@ func (s *stmtWrapper) NumInput() int {
 
 // Query implements driver.Stmt.
 func (s *stmtWrapper) Query(args []driver.Value) (driver.Rows, error) {
-	lg := s.logger.With(slog.String("args", fmt.Sprintf("%+v", args)))
+	lg := s.logger.withArgs(args)
 	var rows driver.Rows
 	err := ignoreAttr(lg.StepWithoutContext(&s.options.Query, func() (*slog.Attr, error) {
 		var err error
@@ -131,7 +141,7 @@ var _ driver.StmtExecContext = (*stmtExecContextWrapperImpl)(nil)
 
 // ExecContext implements driver.StmtExecContext.
 func (s *stmtExecContextWrapperImpl) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
-	lg := s.logger.With(slog.String("args", fmt.Sprintf("%+v", args)))
+	lg := s.logger.withArgs(args)
 	var result driver.Result
 	err := ignoreAttr(lg.Step(ctx, &s.options.ExecContext, func() (*slog.Attr, error) {
 		var err error
@@ -141,6 +151,7 @@ func (s *stmtExecContextWrapperImpl) ExecContext(ctx context.Context, args []dri
 	if err != nil {
 		return nil, err
 	}
+	trackResult(ctx, result)
 	return result, nil
 }
 
@@ -154,7 +165,7 @@ var _ driver.StmtQueryContext = (*stmtQueryContextWrapperImpl)(nil)
 
 // QueryContext implements driver.StmtQueryContext.
 func (s *stmtQueryContextWrapperImpl) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
-	lg := s.logger.With(slog.String("args", fmt.Sprintf("%+v", args)))
+	lg := s.logger.withArgs(args)
 	var rows driver.Rows
 	err := ignoreAttr(lg.Step(ctx, &s.options.QueryContext, func() (*slog.Attr, error) {
 		var err error
@@ -164,7 +175,7 @@ func (s *stmtQueryContextWrapperImpl) QueryContext(ctx context.Context, args []d
 	if err != nil {
 		return nil, err
 	}
-	return wrapRows(rows, s.logger, s.options.Rows), nil
+	return wrapRows(rows, s.logger.withContext(ctx), s.options.Rows), nil
 }
 
 type stmtExecContextWrapper struct {
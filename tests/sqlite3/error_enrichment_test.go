@@ -0,0 +1,51 @@
+package main_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	sqlslog "github.com/akm/sql-slog"
+	"github.com/akm/sql-slog/tests/testhelper"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorEnrichment(t *testing.T) {
+	dsn := "./sqlite3_error_enrichment_test.db"
+	defer os.Remove(dsn)
+
+	ctx := context.TODO()
+	buf := bytes.NewBuffer(nil)
+	logs := testhelper.NewLogAssertion(buf)
+	db, _, err := sqlslog.Open(ctx, "sqlite3", dsn,
+		append(
+			testhelper.StepEventMsgOptions,
+			sqlslog.HandlerFunc(sqlslog.NewJSONHandler),
+			sqlslog.LogWriter(buf),
+			sqlslog.ErrorEnrichment(true),
+		)...,
+	)
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS users (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO users (id, name) VALUES (?, ?)", 1, "Alice")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO users (id, name) VALUES (?, ?)", 1, "Bob")
+	require.Error(t, err)
+
+	var errorLog map[string]interface{}
+	for _, line := range logs.JsonLines(t) {
+		if line["msg"] == "Conn.ExecContext Error" {
+			errorLog = line
+		}
+	}
+	require.NotNil(t, errorLog)
+	assert.Equal(t, "1555", errorLog["error_code"])
+	assert.Equal(t, "constraint_violation", errorLog["error_class"])
+	assert.NotContains(t, errorLog, "sqlstate")
+}
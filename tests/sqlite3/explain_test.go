@@ -0,0 +1,101 @@
+package main_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sqlslog "github.com/akm/sql-slog"
+	"github.com/akm/sql-slog/tests/testhelper"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) lines(t *testing.T) []map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var r []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &m))
+		r = append(r, m)
+	}
+	return r
+}
+
+func TestExplainSlowQueries(t *testing.T) {
+	dsn := "./sqlite3_explain_test.db"
+	defer os.Remove(dsn)
+
+	ctx := context.TODO()
+	buf := &syncBuffer{}
+	db, _, err := sqlslog.Open(ctx, "sqlite3", dsn,
+		append(
+			testhelper.StepEventMsgOptions,
+			sqlslog.HandlerFunc(sqlslog.NewJSONHandler),
+			sqlslog.LogWriter(buf),
+			sqlslog.ExplainSlowQueries(time.Nanosecond),
+		)...,
+	)
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS users (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO users (id, name) VALUES (?, ?)", 1, "Alice")
+	require.NoError(t, err)
+
+	var name string
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT name FROM users WHERE id = ?", 1).Scan(&name))
+	assert.Equal(t, "Alice", name)
+
+	findExplain := func() map[string]interface{} {
+		for _, line := range buf.lines(t) {
+			if line["msg"] == "Conn.QueryContext Explain" {
+				return line
+			}
+		}
+		return nil
+	}
+	var explain map[string]interface{}
+	require.Eventually(t, func() bool {
+		explain = findExplain()
+		return explain != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, "INFO", explain["level"])
+	assert.Equal(t, "SELECT name FROM users WHERE id = ?", explain["query"])
+	require.IsType(t, []interface{}{}, explain["plan"])
+	plan := explain["plan"].([]interface{})
+	require.Len(t, plan, 1)
+	assert.Contains(t, plan[0], "SEARCH users USING INTEGER PRIMARY KEY (rowid=?)")
+
+	var opIDs []interface{}
+	for _, line := range buf.lines(t) {
+		if line["msg"] == "Conn.QueryContext Complete" {
+			opIDs = append(opIDs, line["op_id"])
+		}
+		if line["msg"] == "Conn.ExecContext Complete" {
+			assert.NotContains(t, line, "op_id")
+		}
+	}
+	require.Len(t, opIDs, 1)
+	assert.Equal(t, explain["op_id"], opIDs[0])
+}
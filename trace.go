@@ -0,0 +1,60 @@
+package sqlslog
+
+import (
+	"context"
+	"log/slog"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// Tracing sets whether each step is run in a [runtime/trace] region
+// with [runtime/pprof] labels, so that `go tool trace` and CPU profiles
+// show which SQL statements cost what.
+// The region is named by the [Step], and the labels are [PprofLabelStep],
+// [PprofLabelQueryFingerprint] for the steps which run a query
+// and [PprofLabelQueryName] for the queries with the name annotation. See [QueryName] for details.
+// The default is false.
+func Tracing(v bool) Option {
+	return func(o *options) { o.stepLoggerOptions.tracing = v }
+}
+
+const (
+	PprofLabelStep             = "sql_step"          // pprof label for the step name.
+	PprofLabelQueryFingerprint = "query_fingerprint" // pprof label for the query fingerprint.
+	PprofLabelQueryName        = "query_name"        // pprof label for the query name.
+)
+
+const traceRegionDefault = "sqlslog"
+
+// invoke calls fn in the trace region with pprof labels if tracing is enabled.
+func (x *stepLogger) invoke(ctx context.Context, step *StepOptions, fn func() (*slog.Attr, error)) (*slog.Attr, error) {
+	if !x.options.tracing {
+		return fn()
+	}
+	region := step.step.String()
+	if region == "" {
+		region = traceRegionDefault
+	}
+	var attr *slog.Attr
+	var err error
+	pprof.Do(ctx, pprof.Labels(x.pprofLabels(region)...), func(ctx context.Context) {
+		trace.WithRegion(ctx, region, func() {
+			if x.query != nil {
+				trace.Log(ctx, PprofLabelQueryFingerprint, x.query.Fingerprint())
+			}
+			attr, err = fn()
+		})
+	})
+	return attr, err
+}
+
+func (x *stepLogger) pprofLabels(step string) []string {
+	r := []string{PprofLabelStep, step}
+	if x.query != nil {
+		r = append(r, PprofLabelQueryFingerprint, x.query.Fingerprint())
+		if x.query.name != nil {
+			r = append(r, PprofLabelQueryName, x.query.name.name)
+		}
+	}
+	return r
+}
@@ -0,0 +1,134 @@
+package sqlslog
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"runtime/pprof"
+	"runtime/trace"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTracing(t *testing.T) {
+	t.Parallel()
+	if newOptions("dummy").stepLoggerOptions.tracing {
+		t.Fatal("Expected tracing to be disabled by default")
+	}
+	if !newOptions("dummy", Tracing(true)).stepLoggerOptions.tracing {
+		t.Fatal("Expected tracing to be enabled")
+	}
+}
+
+func TestStepLoggerInvokeWithTracing(t *testing.T) { // nolint:paralleltest
+	buf := bytes.NewBuffer(nil)
+	if err := trace.Start(buf); err != nil {
+		t.Fatalf("Failed to start trace: %v", err)
+	}
+	defer trace.Stop()
+
+	opts := defaultStepLoggerOptions()
+	opts.tracing = true
+	logger := newStepLogger(slog.New(NewTextHandler(bytes.NewBuffer(nil), nil)), opts).withQuery("SELECT 1")
+	stepOptions := defaultStepOptions(StepEventMsgWithoutEventName, StepConnQueryContext, LevelInfo)
+
+	expected := errors.New("unexpected error")
+	_, err := logger.Step(context.Background(), stepOptions, func() (*slog.Attr, error) {
+		return nil, expected
+	})
+	if !errors.Is(err, expected) {
+		t.Fatalf("Expected %v, got %v", expected, err)
+	}
+
+	attr, err := logger.Step(context.Background(), &StepOptions{}, func() (*slog.Attr, error) {
+		r := slog.Bool("ok", true)
+		return &r, nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if attr == nil || attr.Key != "ok" {
+		t.Fatalf("Unexpected attr: %v", attr)
+	}
+}
+
+func TestStepLoggerPprofLabels(t *testing.T) {
+	t.Parallel()
+	logger := newStepLogger(slog.Default(), defaultStepLoggerOptions())
+	for _, tc := range []struct {
+		logger   *stepLogger
+		step     string
+		expected []string
+	}{
+		{logger, "Conn.Ping", []string{PprofLabelStep, "Conn.Ping"}},
+		{
+			logger.withQuery("SELECT 1"), "Conn.QueryContext",
+			[]string{PprofLabelStep, "Conn.QueryContext", PprofLabelQueryFingerprint, queryFingerprint(SQLSyntaxGeneric, "SELECT 1")},
+		},
+		{
+			logger.withQuery("-- name: GetOne :one\nSELECT 1"), "Stmt.QueryContext",
+			[]string{
+				PprofLabelStep, "Stmt.QueryContext",
+				PprofLabelQueryFingerprint, queryFingerprint(SQLSyntaxGeneric, "-- name: GetOne :one\nSELECT 1"),
+				PprofLabelQueryName, "GetOne",
+			},
+		},
+	} {
+		if actual := tc.logger.pprofLabels(tc.step); !slices.Equal(actual, tc.expected) {
+			t.Errorf("Expected %v, got %v", tc.expected, actual)
+		}
+	}
+}
+
+// mockLabelConn records the pprof labels of the goroutines while the driver runs a query.
+type mockLabelConn struct {
+	mockResultConn
+	labels string
+}
+
+func (m *mockLabelConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "# labels: ") {
+			m.labels += line + "\n"
+		}
+	}
+	return m.mockResultConn.QueryContext(ctx, query, args)
+}
+
+func TestTracingPprofLabelsInDriver(t *testing.T) {
+	t.Parallel()
+	run := func(t *testing.T, query string, opts ...Option) string {
+		t.Helper()
+		o := newOptions("sqlite3", opts...)
+		logger := newStepLogger(slog.New(NewTextHandler(io.Discard, nil)), o.stepLoggerOptions)
+		conn := &mockLabelConn{}
+		if _, err := wrapConn(conn, logger, o.DriverOptions.ConnOptions).(driver.QueryerContext).QueryContext(context.Background(), query, nil); err != nil {
+			t.Fatal(err)
+		}
+		return conn.labels
+	}
+	// The queries are unique to this test because the labels of the other goroutines are also recorded.
+	t.Run("enabled", func(t *testing.T) {
+		t.Parallel()
+		query := "-- name: TracingEnabled :many\nSELECT id FROM tracing_enabled"
+		expected := `{"query_fingerprint":"` + queryFingerprint(SQLSyntaxSQLite, query) + `", "query_name":"TracingEnabled", "sql_step":"Conn.QueryContext"}`
+		if labels := run(t, query, Tracing(true)); !strings.Contains(labels, expected) {
+			t.Errorf("Expected %q in %q", expected, labels)
+		}
+	})
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		query := "SELECT id FROM tracing_disabled"
+		if labels := run(t, query); strings.Contains(labels, queryFingerprint(SQLSyntaxSQLite, query)) {
+			t.Errorf("Unexpected labels in %q", labels)
+		}
+	})
+}
@@ -17,23 +17,38 @@ func defaultTxOptions(msgb StepEventMsgBuilder) *txOptions {
 }
 
 func wrapTx(original driver.Tx, logger *stepLogger, options *txOptions) *txWrapper {
-	return &txWrapper{original: original, logger: logger, options: options}
+	t := &txWrapper{original: original, logger: logger, options: options}
+	t.leak = logger.trackLeak(t, leakObjectTx)
+	t.entry = logger.register(openObjectTx, "")
+	logger.conn.setTx(t.entry)
+	return t
 }
 
 type txWrapper struct {
 	original driver.Tx
 	logger   *stepLogger
 	options  *txOptions
+	leak     *leakRecord
+	entry    *openObject
 }
 
 var _ driver.Tx = (*txWrapper)(nil)
 
 // Commit implements driver.Tx.
 func (t *txWrapper) Commit() error {
+	t.finish()
 	return ignoreAttr(t.logger.StepWithoutContext(&t.options.Commit, withNilAttr(t.original.Commit)))
 }
 
 // Rollback implements driver.Tx.
 func (t *txWrapper) Rollback() error {
+	t.finish()
 	return ignoreAttr(t.logger.StepWithoutContext(&t.options.Rollback, withNilAttr(t.original.Rollback)))
 }
+
+// finish marks the transaction as finished for the leak detection and the registry.
+func (t *txWrapper) finish() {
+	t.leak.close()
+	t.entry.unregister()
+	t.logger.conn.setTx(nil)
+}